@@ -8,18 +8,23 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"pokerDB/pkg/models"
+	"pokerDB/pkg/rules/play"
 	"pokerDB/pkg/utils"
 	"time"
 )
 
 func main() {
-	game := models.Game{
-		ID:           uuid.UUID{},
-		TableID:      uuid.UUID{},
-		CardSequence: nil,
-		StartedTime:  time.Time{},
-		EndedTime:    time.Time{},
-		PersonCount:  3,
+	game := models.NewGame(uuid.New(), 3)
+	game.SmallBlind = 50
+	game.BigBlind = 100
+	game.MinBuyIn = 1000
+	game.MaxBuyIn = 10000
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := game.BuyIn(p, 1000); err != nil {
+			logrus.Debug("Buy-in error: ", err)
+			return
+		}
 	}
 
 	jsonData, _ := json.Marshal(game)
@@ -32,19 +37,48 @@ func main() {
 
 	fmt.Println("Game started at", game.StartedTime.Format(time.RFC3339))
 
-	hands := game.DealHands()
+	hands, err := game.DealHoleCards()
+	if err != nil {
+		logrus.Debug("Deal error: ", err)
+		return
+	}
 	for i, h := range hands {
 		fmt.Printf("Player %d: %s %s\n", i+1, utils.CardToString(h[0]), utils.CardToString(h[1]))
 	}
 
-	flop := game.Deal(3)
-	fmt.Printf("Flop: %s %s %s\n", utils.CardToString(flop[0]), utils.CardToString(flop[1]), utils.CardToString(flop[2]))
-
-	turn := game.Deal(1)
-	fmt.Printf("Turn: %s\n", utils.CardToString(turn[0]))
+	for _, street := range []string{"Flop", "Turn", "River"} {
+		for !game.BettingClosed() {
+			if err := checkOrCall(game); err != nil {
+				logrus.Debug("Action error: ", err)
+				return
+			}
+		}
+		cards, err := game.NextStreet()
+		if err != nil {
+			logrus.Debug("Deal error: ", err)
+			return
+		}
+		fmt.Printf("%s:", street)
+		for _, c := range cards {
+			fmt.Printf(" %s", utils.CardToString(c))
+		}
+		fmt.Println()
+	}
 
-	river := game.Deal(1)
-	fmt.Printf("River: %s\n", utils.CardToString(river[0]))
+	for !game.BettingClosed() {
+		if err := checkOrCall(game); err != nil {
+			logrus.Debug("Action error: ", err)
+			return
+		}
+	}
+	results, err := play.NewGameResolver(game).Resolve()
+	if err != nil {
+		logrus.Debug("Showdown error: ", err)
+		return
+	}
+	for i, r := range results {
+		fmt.Printf("Pot %d of %d won by %v\n", i+1, r.Pot.Amount, r.Winners)
+	}
 
 	if err := game.End(); err != nil {
 		logrus.Debug("Game end error: ", err)
@@ -54,3 +88,13 @@ func main() {
 	fmt.Println("Game ended at", game.EndedTime.Format(time.RFC3339))
 
 }
+
+// checkOrCall checks, or calls the current bet, for the player to act.
+func checkOrCall(game *models.Game) error {
+	for _, a := range game.LegalActions() {
+		if a.Code == models.ActionCheck {
+			return game.AddAction(game.ActionOn(), models.ActionCheck, a.Max)
+		}
+	}
+	return fmt.Errorf("player cannot check or call")
+}
@@ -0,0 +1,615 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"pokerDB/pkg/rules/betting"
+	"pokerDB/pkg/rules/rake"
+	"pokerDB/pkg/utils"
+)
+
+// EntryKind tells the kinds of action log entries apart.
+type EntryKind int
+
+const (
+	EntryPlayer  EntryKind = iota // action taken by or for a player
+	EntryStart                    // G: game start with the game options
+	EntryStreet                   // D: street or extra board dealt
+	EntryEnd                      // E: game end with the ledger balances
+	EntryVersion                  // V: log format header
+	EntryRake                     // K: rake taken from a hand
+)
+
+// Action log format versions. Version 1 logs have no header, truncate
+// player IDs to eight characters and use Unix seconds. Version 2 logs
+// start with a "V:2" header and prefix every entry with its sequence
+// number, the entry's index in the log: "<seq>;<body>,<unix millis>".
+// Player IDs are written in full.
+const (
+	LogV1 = 1
+	LogV2 = 2
+
+	// LogVersion is the format used for new logs.
+	LogVersion = LogV2
+)
+
+// Errors returned by ParseAction. They are wrapped with details about the
+// offending part of the entry.
+var (
+	ErrMalformedEntry = errors.New("malformed entry")
+	ErrBadTimestamp   = errors.New("bad timestamp")
+	ErrBadPlayer      = errors.New("bad player id")
+	ErrUnknownCode    = errors.New("unknown action code")
+	ErrBadAmount      = errors.New("bad amount")
+	ErrBadSequence    = errors.New("bad sequence number")
+	ErrBadVersion     = errors.New("unsupported log version")
+)
+
+// Action is a single decoded action log entry. Player, Code and Amount are
+// set for player actions; Start, Street and Balances hold the payload of
+// the start, street and end entries. Version is the log format the entry
+// is written in and Seq its sequence number in version 2 logs.
+type Action struct {
+	Kind     EntryKind
+	Version  int
+	Seq      int64
+	Player   string // player ID, truncated in version 1 logs
+	Code     string
+	Amount   int64
+	Time     time.Time
+	Start    *GameStart
+	Street   *StreetDeal
+	Balances []LedgerBalance
+	Seed     string // server seed revealed in the end entry of a fair shuffle
+}
+
+// GameStart holds the game options recorded when a game starts. The seats
+// are only recorded for games continuing a table and are zero otherwise.
+// Shuffler names the shuffler that produced the deck and is written as a
+// trailing "shuffle=<name>" field; logs from before it was recorded leave it
+// empty. A FairShuffler adds its commitment and the client seeds as
+// "commit=<hex>" and "clients=<seed>.<seed>". Deal is the deal mode, written
+// as "deal=<mode>" unless it is DealLegacy. The rake rules that are set are
+// written as "rake=<basis points>", "rakecap=<chips>",
+// "rakecaps=<players>x<cap>.<players>x<cap>", "nfnd=1" and "fee=<chips>".
+// The action clock is written as "clock=<ms>" and "bank=<ms>" when it is
+// used. Pot-limit and fixed-limit games write their structure as
+// "limit=pl" or "limit=fl" followed by the bet sizes and raise cap that are
+// set as "smallbet=<chips>", "bigbet=<chips>" and "raisecap=<bets>". Games
+// other than hold'em write their variant as "game=<variant>".
+type GameStart struct {
+	SmallBlind     int64
+	BigBlind       int64
+	Ante           int64
+	RunItTwice     bool
+	Straddle       bool
+	Betting        betting.Limits
+	Variant        Variant
+	ButtonSeat     int
+	SmallBlindSeat int
+	BigBlindSeat   int
+	Shuffler       string
+	Commitment     string
+	ClientSeeds    []string
+	Deal           string
+	Rake           rake.Rules
+	DecisionTime   time.Duration
+	TimeBank       time.Duration
+}
+
+// StreetDeal names the street or extra run-it-twice board ("run2") that was
+// dealt together with its new community cards.
+type StreetDeal struct {
+	Name  string
+	Cards []int
+}
+
+// LedgerBalance is a player's final balance recorded in the end entry.
+type LedgerBalance struct {
+	Player  string // player ID, truncated in version 1 logs, or HousePlayer
+	Balance int64
+}
+
+// ParseAction decodes an action log entry of either format: a player action
+// "<id><code><amount>,<time>", one of the G:, D: and E: entries or the V:
+// header, with a "<seq>;" prefix in version 2 logs.
+func ParseAction(entry string) (Action, error) {
+	i := strings.LastIndex(entry, ",")
+	if i < 0 {
+		return Action{}, fmt.Errorf("%w: missing timestamp", ErrMalformedEntry)
+	}
+	body := entry[:i]
+	ts, err := strconv.ParseInt(entry[i+1:], 10, 64)
+	if err != nil {
+		return Action{}, fmt.Errorf("%w: %q", ErrBadTimestamp, entry[i+1:])
+	}
+	a := Action{Version: LogV1, Time: time.Unix(ts, 0)}
+	if strings.HasPrefix(body, "V:") {
+		a.Kind = EntryVersion
+		a.Version, err = strconv.Atoi(body[2:])
+		if err != nil || a.Version != LogV2 {
+			return Action{}, fmt.Errorf("%w: %q", ErrBadVersion, body[2:])
+		}
+		a.Time = time.UnixMilli(ts)
+		return a, nil
+	}
+	if j := strings.Index(body, ";"); j >= 0 {
+		a.Version = LogV2
+		a.Time = time.UnixMilli(ts)
+		a.Seq, err = strconv.ParseInt(body[:j], 10, 64)
+		if err != nil || a.Seq < 0 {
+			return Action{}, fmt.Errorf("%w: %q", ErrBadSequence, body[:j])
+		}
+		body = body[j+1:]
+	}
+	switch {
+	case strings.HasPrefix(body, "G:"):
+		return a, parseStart(&a, body[2:])
+	case strings.HasPrefix(body, "D:"):
+		return a, parseStreet(&a, body[2:])
+	case strings.HasPrefix(body, "E:"):
+		return a, parseEnd(&a, body[2:])
+	case strings.HasPrefix(body, "K:"):
+		a.Kind = EntryRake
+		a.Amount, err = strconv.ParseInt(body[2:], 10, 64)
+		if err != nil || a.Amount < 0 {
+			return Action{}, fmt.Errorf("%w: %q", ErrBadAmount, body[2:])
+		}
+		return a, nil
+	}
+
+	n := playerIDLength(a.Version)
+	if len(body) < n+2 {
+		return Action{}, fmt.Errorf("%w: body %q too short", ErrMalformedEntry, body)
+	}
+	a.Kind = EntryPlayer
+	if a.Player, err = parsePlayer(body[:n], a.Version); err != nil {
+		return Action{}, err
+	}
+	a.Code = string(body[n])
+	if _, ok := ActionWords[a.Code]; !ok {
+		return Action{}, fmt.Errorf("%w: %q", ErrUnknownCode, a.Code)
+	}
+	a.Amount, err = strconv.ParseInt(body[n+1:], 10, 64)
+	if err != nil || a.Amount < 0 {
+		return Action{}, fmt.Errorf("%w: %q", ErrBadAmount, body[n+1:])
+	}
+	return a, nil
+}
+
+// playerIDLength returns the length of player IDs in a log version.
+func playerIDLength(version int) int {
+	if version >= LogV2 {
+		return 36
+	}
+	return 8
+}
+
+func parseStart(a *Action, body string) error {
+	a.Kind = EntryStart
+	fields := strings.Split(body, ":")
+	named := len(fields)
+	for named > 0 && strings.Contains(fields[named-1], "=") {
+		named--
+	}
+	options := fields[named:]
+	fields = fields[:named]
+	if len(fields) != 5 && len(fields) != 8 {
+		return fmt.Errorf("%w: start entry has %d fields", ErrMalformedEntry, len(fields))
+	}
+	values := make([]int64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseInt(f, 10, 64)
+		if err != nil || v < 0 {
+			return fmt.Errorf("%w: start field %d %q", ErrBadAmount, i, f)
+		}
+		values[i] = v
+	}
+	a.Start = &GameStart{
+		SmallBlind: values[0],
+		BigBlind:   values[1],
+		Ante:       values[2],
+		RunItTwice: values[3] == 1,
+		Straddle:   values[4] == 1,
+	}
+	if len(values) == 8 {
+		a.Start.ButtonSeat = int(values[5])
+		a.Start.SmallBlindSeat = int(values[6])
+		a.Start.BigBlindSeat = int(values[7])
+	}
+	for _, o := range options {
+		kv := strings.SplitN(o, "=", 2)
+		switch {
+		case kv[0] == "shuffle" && kv[1] != "":
+			a.Start.Shuffler = kv[1]
+		case kv[0] == "commit" && kv[1] != "":
+			a.Start.Commitment = kv[1]
+		case kv[0] == "clients" && kv[1] != "":
+			a.Start.ClientSeeds = strings.Split(kv[1], ".")
+		case kv[0] == "deal" && (kv[1] == DealStandard || kv[1] == DealPerHand):
+			a.Start.Deal = kv[1]
+		case kv[0] == "rakecaps":
+			for _, c := range strings.Split(kv[1], ".") {
+				pc := strings.SplitN(c, "x", 2)
+				if len(pc) != 2 {
+					return fmt.Errorf("%w: rake cap %q", ErrMalformedEntry, c)
+				}
+				players, err := strconv.Atoi(pc[0])
+				if err != nil || players < 0 {
+					return fmt.Errorf("%w: rake cap %q", ErrBadAmount, c)
+				}
+				limit, err := strconv.ParseInt(pc[1], 10, 64)
+				if err != nil || limit < 0 {
+					return fmt.Errorf("%w: rake cap %q", ErrBadAmount, c)
+				}
+				a.Start.Rake.PlayerCaps = append(a.Start.Rake.PlayerCaps, rake.PlayerCap{Players: players, Cap: limit})
+			}
+		case kv[0] == "game":
+			v := Variant(kv[1])
+			if v == VariantHoldem || v.Validate() != nil {
+				return fmt.Errorf("%w: unknown variant %q", ErrMalformedEntry, kv[1])
+			}
+			a.Start.Variant = v
+		case kv[0] == "limit":
+			s, err := betting.ParseStructure(kv[1])
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrMalformedEntry, err)
+			}
+			a.Start.Betting.Structure = s
+		case kv[0] == "smallbet" || kv[0] == "bigbet" || kv[0] == "raisecap":
+			v, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil || v < 0 {
+				return fmt.Errorf("%w: start option %q", ErrBadAmount, o)
+			}
+			switch kv[0] {
+			case "smallbet":
+				a.Start.Betting.SmallBet = v
+			case "bigbet":
+				a.Start.Betting.BigBet = v
+			case "raisecap":
+				a.Start.Betting.RaiseCap = int(v)
+			}
+		case kv[0] == "clock" || kv[0] == "bank":
+			v, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil || v < 0 {
+				return fmt.Errorf("%w: start option %q", ErrBadAmount, o)
+			}
+			if kv[0] == "clock" {
+				a.Start.DecisionTime = time.Duration(v) * time.Millisecond
+			} else {
+				a.Start.TimeBank = time.Duration(v) * time.Millisecond
+			}
+		case kv[0] == "rake" || kv[0] == "rakecap" || kv[0] == "nfnd" || kv[0] == "fee":
+			v, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil || v < 0 {
+				return fmt.Errorf("%w: start option %q", ErrBadAmount, o)
+			}
+			switch kv[0] {
+			case "rake":
+				a.Start.Rake.Percent = v
+			case "rakecap":
+				a.Start.Rake.Cap = v
+			case "nfnd":
+				a.Start.Rake.NoFlopNoDrop = v == 1
+			case "fee":
+				a.Start.Rake.Fee = v
+			}
+		default:
+			return fmt.Errorf("%w: start option %q", ErrMalformedEntry, o)
+		}
+	}
+	return nil
+}
+
+func parseStreet(a *Action, body string) error {
+	a.Kind = EntryStreet
+	fields := strings.Split(body, ":")
+	if fields[0] == "" {
+		return fmt.Errorf("%w: street entry without a street", ErrMalformedEntry)
+	}
+	a.Street = &StreetDeal{Name: fields[0], Cards: []int{}}
+	for _, f := range fields[1:] {
+		c, err := strconv.Atoi(f)
+		if err != nil || c < 1 || c > 52 {
+			return fmt.Errorf("%w: card %q", ErrMalformedEntry, f)
+		}
+		a.Street.Cards = append(a.Street.Cards, c)
+	}
+	return nil
+}
+
+func parseEnd(a *Action, body string) error {
+	a.Kind = EntryEnd
+	a.Balances = []LedgerBalance{}
+	if body == "" {
+		return nil
+	}
+	for _, pair := range strings.Split(body, ":") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%w: ledger %q", ErrMalformedEntry, pair)
+		}
+		if kv[0] == "seed" && kv[1] != "" {
+			a.Seed = kv[1]
+			continue
+		}
+		player := HousePlayer
+		if kv[0] != HousePlayer {
+			var err error
+			if player, err = parsePlayer(kv[0], a.Version); err != nil {
+				return err
+			}
+		}
+		balance, err := strconv.ParseInt(kv[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: ledger balance %q", ErrBadAmount, kv[1])
+		}
+		a.Balances = append(a.Balances, LedgerBalance{Player: player, Balance: balance})
+	}
+	return nil
+}
+
+// rakeOptions returns the start entry fields of the rake rules that are set.
+func rakeOptions(r rake.Rules) []string {
+	fields := []string{}
+	if r.Percent > 0 {
+		fields = append(fields, "rake="+strconv.FormatInt(r.Percent, 10))
+	}
+	if r.Cap > 0 {
+		fields = append(fields, "rakecap="+strconv.FormatInt(r.Cap, 10))
+	}
+	if len(r.PlayerCaps) > 0 {
+		caps := make([]string, len(r.PlayerCaps))
+		for i, c := range r.PlayerCaps {
+			caps[i] = fmt.Sprintf("%dx%d", c.Players, c.Cap)
+		}
+		fields = append(fields, "rakecaps="+strings.Join(caps, "."))
+	}
+	if r.NoFlopNoDrop {
+		fields = append(fields, "nfnd=1")
+	}
+	if r.Fee > 0 {
+		fields = append(fields, "fee="+strconv.FormatInt(r.Fee, 10))
+	}
+	return fields
+}
+
+// bettingOptions returns the start entry fields of a pot-limit or
+// fixed-limit structure.
+func bettingOptions(l betting.Limits) []string {
+	if l.Structure == betting.NoLimit {
+		return nil
+	}
+	fields := []string{"limit=" + l.Structure.String()}
+	if l.SmallBet > 0 {
+		fields = append(fields, "smallbet="+strconv.FormatInt(l.SmallBet, 10))
+	}
+	if l.BigBet > 0 {
+		fields = append(fields, "bigbet="+strconv.FormatInt(l.BigBet, 10))
+	}
+	if l.RaiseCap > 0 {
+		fields = append(fields, "raisecap="+strconv.Itoa(l.RaiseCap))
+	}
+	return fields
+}
+
+// parsePlayer checks a player ID: a full UUID in version 2 logs and the
+// first eight hex digits of one in version 1 logs.
+func parsePlayer(id string, version int) (string, error) {
+	if version >= LogV2 {
+		if _, err := uuid.Parse(id); err != nil || len(id) != 36 {
+			return "", fmt.Errorf("%w: %q", ErrBadPlayer, id)
+		}
+		return id, nil
+	}
+	if len(id) != 8 {
+		return "", fmt.Errorf("%w: %q", ErrBadPlayer, id)
+	}
+	for _, c := range id {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return "", fmt.Errorf("%w: %q", ErrBadPlayer, id)
+		}
+	}
+	return id, nil
+}
+
+// Encode returns the action log entry for the action in its version's
+// format. ParseAction(a.Encode()) returns an equal action.
+func (a Action) Encode() string {
+	if a.Kind == EntryVersion {
+		return fmt.Sprintf("V:%d,%d", a.Version, a.Time.UnixMilli())
+	}
+	var body string
+	switch a.Kind {
+	case EntryStart:
+		s := a.Start
+		if s == nil {
+			s = &GameStart{}
+		}
+		fields := []string{"G", strconv.FormatInt(s.SmallBlind, 10), strconv.FormatInt(s.BigBlind, 10), strconv.FormatInt(s.Ante, 10), strconv.Itoa(boolToInt(s.RunItTwice)), strconv.Itoa(boolToInt(s.Straddle))}
+		if s.BigBlindSeat > 0 {
+			fields = append(fields, strconv.Itoa(s.ButtonSeat), strconv.Itoa(s.SmallBlindSeat), strconv.Itoa(s.BigBlindSeat))
+		}
+		if s.Shuffler != "" {
+			fields = append(fields, "shuffle="+s.Shuffler)
+		}
+		if s.Commitment != "" {
+			fields = append(fields, "commit="+s.Commitment)
+		}
+		if len(s.ClientSeeds) > 0 {
+			fields = append(fields, "clients="+strings.Join(s.ClientSeeds, "."))
+		}
+		if s.Deal != DealLegacy {
+			fields = append(fields, "deal="+s.Deal)
+		}
+		fields = append(fields, rakeOptions(s.Rake)...)
+		if s.DecisionTime > 0 {
+			fields = append(fields, "clock="+strconv.FormatInt(s.DecisionTime.Milliseconds(), 10))
+		}
+		if s.TimeBank > 0 {
+			fields = append(fields, "bank="+strconv.FormatInt(s.TimeBank.Milliseconds(), 10))
+		}
+		fields = append(fields, bettingOptions(s.Betting)...)
+		if s.Variant != VariantHoldem {
+			fields = append(fields, "game="+string(s.Variant))
+		}
+		body = strings.Join(fields, ":")
+	case EntryStreet:
+		fields := []string{"D"}
+		if a.Street != nil {
+			fields = append(fields, a.Street.Name)
+			for _, c := range a.Street.Cards {
+				fields = append(fields, strconv.Itoa(c))
+			}
+		}
+		body = strings.Join(fields, ":")
+	case EntryEnd:
+		pairs := make([]string, len(a.Balances))
+		for i, b := range a.Balances {
+			pairs[i] = fmt.Sprintf("%s=%d", b.Player, b.Balance)
+		}
+		if a.Seed != "" {
+			pairs = append(pairs, "seed="+a.Seed)
+		}
+		body = "E:" + strings.Join(pairs, ":")
+	case EntryRake:
+		body = fmt.Sprintf("K:%d", a.Amount)
+	default:
+		body = fmt.Sprintf("%s%s%d", a.Player, a.Code, a.Amount)
+	}
+	if a.Version >= LogV2 {
+		return fmt.Sprintf("%d;%s,%d", a.Seq, body, a.Time.UnixMilli())
+	}
+	return fmt.Sprintf("%s,%d", body, a.Time.Unix())
+}
+
+// String formats the action as a human readable line.
+func (a Action) String() string {
+	at := a.Time.Format(time.RFC3339)
+	if a.Version >= LogV2 {
+		at = a.Time.Format("2006-01-02T15:04:05.000Z07:00")
+	}
+	switch a.Kind {
+	case EntryVersion:
+		return fmt.Sprintf("log version %d at %s", a.Version, at)
+	case EntryRake:
+		return fmt.Sprintf("rake %d at %s", a.Amount, at)
+	case EntryStart:
+		s := a.Start
+		if s == nil {
+			s = &GameStart{}
+		}
+		line := fmt.Sprintf("start sb=%d bb=%d ante=%d runTwice=%d straddle=%d", s.SmallBlind, s.BigBlind, s.Ante, boolToInt(s.RunItTwice), boolToInt(s.Straddle))
+		if s.Shuffler != "" {
+			line += " shuffle=" + s.Shuffler
+		}
+		if s.DecisionTime > 0 {
+			line += fmt.Sprintf(" clock=%s bank=%s", s.DecisionTime, s.TimeBank)
+		}
+		if s.Variant != VariantHoldem {
+			line += " game=" + string(s.Variant)
+		}
+		if s.Betting.Structure != betting.NoLimit {
+			line += " limit=" + s.Betting.Structure.String()
+		}
+		return line + " at " + at
+	case EntryStreet:
+		line := "deal"
+		if a.Street != nil {
+			line += " " + a.Street.Name
+			for _, c := range a.Street.Cards {
+				line += " " + utils.CardToString(c)
+			}
+		}
+		return line + " at " + at
+	case EntryEnd:
+		pairs := make([]string, len(a.Balances))
+		for i, b := range a.Balances {
+			pairs[i] = fmt.Sprintf("%s=%d", b.Player, b.Balance)
+		}
+		if a.Seed != "" {
+			return fmt.Sprintf("result %v seed=%s at %s", pairs, a.Seed, at)
+		}
+		return fmt.Sprintf("result %v at %s", pairs, at)
+	}
+	return fmt.Sprintf("%s %s %d at %s", a.Player, ActionToWord(a.Code), a.Amount, at)
+}
+
+// Hands returns the number of hands dealt in the log, counted by the
+// preflop street entries that start them.
+func (l ActionLog) Hands() int {
+	hands := 0
+	for _, entry := range l {
+		if a, err := ParseAction(entry); err == nil && a.Kind == EntryStreet && a.Street.Name == StreetPreflop.String() {
+			hands++
+		}
+	}
+	return hands
+}
+
+// Version returns the format version of the log: the version named in its
+// header, LogV1 for logs without one, or 0 for an empty log.
+func (l ActionLog) Version() int {
+	if len(l) == 0 {
+		return 0
+	}
+	if a, err := ParseAction(l[0]); err == nil && a.Kind == EntryVersion {
+		return a.Version
+	}
+	return LogV1
+}
+
+// logActionNoLock logs a player action taking place now. The caller must
+// hold the mutex.
+func (g *Game) logActionNoLock(playerID uuid.UUID, code string, amount int64) {
+	g.logForNoLock(Action{Kind: EntryPlayer, Player: g.logIDNoLock(playerID), Code: code, Amount: amount, Time: g.nowNoLock()}, playerID)
+}
+
+// logIDNoLock returns the player ID as written in the game's log format.
+// The caller must hold the mutex.
+func (g *Game) logIDNoLock(playerID uuid.UUID) string {
+	if g.logVersionNoLock() >= LogV2 {
+		return playerID.String()
+	}
+	return shortID(playerID)
+}
+
+// logVersionNoLock returns the format of the game's log. Empty logs are
+// written in LogVersion unless the game was restored from an older log.
+// The caller must hold the mutex.
+func (g *Game) logVersionNoLock() int {
+	if v := g.ActionLog.Version(); v != 0 {
+		return v
+	}
+	if g.logVersion != 0 {
+		return g.logVersion
+	}
+	return LogVersion
+}
+
+// logNoLock appends an action to the action log in the log's format,
+// starting version 2 logs with their header. The caller must hold the
+// mutex.
+func (g *Game) logNoLock(a Action) {
+	g.logForNoLock(a, uuid.Nil)
+}
+
+// logForNoLock logs an action about a player and queues its event for the
+// subscribers. The caller must hold the mutex.
+func (g *Game) logForNoLock(a Action, playerID uuid.UUID) {
+	a.Version = g.logVersionNoLock()
+	if a.Version >= LogV2 {
+		if len(g.ActionLog) == 0 {
+			header := Action{Kind: EntryVersion, Version: a.Version, Time: a.Time}
+			g.ActionLog = append(g.ActionLog, header.Encode())
+		}
+		a.Seq = int64(len(g.ActionLog))
+	}
+	g.ActionLog = append(g.ActionLog, a.Encode())
+	g.queueEventNoLock(a, playerID)
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pokerDB/pkg/rules/betting"
+	"pokerDB/pkg/rules/rake"
+)
+
+func TestParseActionRoundTrip(t *testing.T) {
+	at := time.Unix(1692300000, 0)
+	id := "c0ffee00-1111-4222-8333-444455556666"
+	v2 := time.UnixMilli(1692300000123)
+	actions := []Action{
+		{Kind: EntryVersion, Version: LogV2, Time: v2},
+		{Kind: EntryPlayer, Version: LogV2, Seq: 1, Player: id, Code: ActionRaise, Amount: 500, Time: v2},
+		{Kind: EntryStart, Version: LogV2, Seq: 2, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Shuffler: "crypto"}, Time: v2},
+		{Kind: EntryStart, Version: LogV2, Seq: 2, Start: &GameStart{SmallBlind: 50, BigBlind: 100, ButtonSeat: 1, SmallBlindSeat: 2, BigBlindSeat: 3, Shuffler: "seeded"}, Time: v2},
+		{Kind: EntryStreet, Version: LogV2, Seq: 3, Street: &StreetDeal{Name: "flop", Cards: []int{12, 33, 5}}, Time: v2},
+		{Kind: EntryEnd, Version: LogV2, Seq: 4, Balances: []LedgerBalance{{Player: id, Balance: -20}}, Time: v2},
+		{Kind: EntryStart, Version: LogV2, Seq: 2, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Shuffler: "fair", Commitment: "ab12", ClientSeeds: []string{"lucky7", "x"}}, Time: v2},
+		{Kind: EntryEnd, Version: LogV2, Seq: 5, Balances: []LedgerBalance{}, Seed: "cd34", Time: v2},
+		{Kind: EntryStart, Version: LogV2, Seq: 2, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Shuffler: "crypto", Deal: DealStandard, Rake: rake.Rules{Percent: 500, Cap: 30, PlayerCaps: []rake.PlayerCap{{Players: 2, Cap: 10}, {Players: 4, Cap: 20}}, NoFlopNoDrop: true, Fee: 5}}, Time: v2},
+		{Kind: EntryPlayer, Version: LogV2, Seq: 1, Player: id, Code: ActionFee, Amount: 5, Time: v2},
+		{Kind: EntryRake, Version: LogV2, Seq: 9, Amount: 15, Time: v2},
+		{Kind: EntryStart, Version: LogV2, Seq: 2, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Shuffler: "crypto", Deal: DealStandard, DecisionTime: 30 * time.Second, TimeBank: 90 * time.Second}, Time: v2},
+		{Kind: EntryPlayer, Version: LogV2, Seq: 8, Player: id, Code: ActionTimeBank, Amount: 15000, Time: v2},
+		{Kind: EntryStart, Version: LogV2, Seq: 2, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Betting: betting.Limits{Structure: betting.PotLimit}, Variant: VariantOmaha5, Shuffler: "crypto", Deal: DealStandard}, Time: v2},
+		{Kind: EntryStart, Version: LogV2, Seq: 2, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Betting: betting.Limits{Structure: betting.FixedLimit, SmallBet: 100, BigBet: 200, RaiseCap: 4}, Shuffler: "crypto", Deal: DealStandard}, Time: v2},
+		{Kind: EntryEnd, Version: LogV2, Seq: 10, Balances: []LedgerBalance{{Player: id, Balance: -20}, {Player: HousePlayer, Balance: 20}}, Time: v2},
+		{Kind: EntryPlayer, Player: "c0ffee00", Code: ActionRaise, Amount: 500, Time: at},
+		{Kind: EntryPlayer, Player: "c0ffee01", Code: ActionFold, Time: at},
+		{Kind: EntryStart, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Ante: 10, RunItTwice: true}, Time: at},
+		{Kind: EntryStart, Start: &GameStart{SmallBlind: 50, BigBlind: 100, Straddle: true, ButtonSeat: 2, SmallBlindSeat: 3, BigBlindSeat: 6}, Time: at},
+		{Kind: EntryStreet, Street: &StreetDeal{Name: "preflop", Cards: []int{}}, Time: at},
+		{Kind: EntryStreet, Street: &StreetDeal{Name: "flop", Cards: []int{12, 33, 5}}, Time: at},
+		{Kind: EntryEnd, Balances: []LedgerBalance{{Player: "c0ffee00", Balance: 1000}, {Player: "c0ffee01", Balance: -1000}}, Time: at},
+		{Kind: EntryEnd, Balances: []LedgerBalance{}, Time: at},
+	}
+	for _, want := range actions {
+		if want.Version == 0 {
+			want.Version = LogV1
+		}
+		entry := want.Encode()
+		got, err := ParseAction(entry)
+		if err != nil {
+			t.Fatalf("parse %s: %v", entry, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip of %s got %+v want %+v", entry, got, want)
+		}
+		if got.Encode() != entry {
+			t.Errorf("expected %s to encode unchanged got %s", entry, got.Encode())
+		}
+	}
+}
+
+func TestParseActionErrors(t *testing.T) {
+	cases := []struct {
+		entry string
+		err   error
+	}{
+		{"c0ffee00R500", ErrMalformedEntry},
+		{"c0ffee00R500,soon", ErrBadTimestamp},
+		{"c0ffee00R,1692300000", ErrMalformedEntry},
+		{"c0ffeeXXR500,1692300000", ErrBadPlayer},
+		{"c0ffee00Z500,1692300000", ErrUnknownCode},
+		{"c0ffee00R-5,1692300000", ErrBadAmount},
+		{"c0ffee00Rlots,1692300000", ErrBadAmount},
+		{"G:50:100:0,1692300000", ErrMalformedEntry},
+		{"G:50:x:0:0:0,1692300000", ErrBadAmount},
+		{"G:50:100:0:0:0:deck=new,1692300000", ErrMalformedEntry},
+		{"G:50:100:0:shuffle=crypto,1692300000", ErrMalformedEntry},
+		{"D:flop:12:99:5,1692300000", ErrMalformedEntry},
+		{"D:,1692300000", ErrMalformedEntry},
+		{"E:c0ffee00,1692300000", ErrMalformedEntry},
+		{"E:c0ffee00=ten,1692300000", ErrBadAmount},
+		{"G:50:100:0:0:0:rake=lots,1692300000", ErrBadAmount},
+		{"G:50:100:0:0:0:rakecaps=2,1692300000", ErrMalformedEntry},
+		{"K:-5,1692300000", ErrBadAmount},
+		{"G:50:100:0:0:0:clock=-1,1692300000", ErrBadAmount},
+		{"G:50:100:0:0:0:limit=xl,1692300000", ErrMalformedEntry},
+		{"G:50:100:0:0:0:game=stud,1692300000", ErrMalformedEntry},
+		{"G:50:100:0:0:0:limit=fl:raisecap=-1,1692300000", ErrBadAmount},
+		{"V:3,1692300000123", ErrBadVersion},
+		{"x;D:preflop,1692300000123", ErrBadSequence},
+		{"1;c0ffee00R500,1692300000123", ErrMalformedEntry},
+		{"1;c0ffee00-1111-4222-8333-44445555666xR500,1692300000123", ErrBadPlayer},
+	}
+	for _, c := range cases {
+		if _, err := ParseAction(c.entry); !errors.Is(err, c.err) {
+			t.Errorf("%s: expected %v got %v", c.entry, c.err, err)
+		}
+	}
+}
+
+func TestActionString(t *testing.T) {
+	a, err := ParseAction("D:flop:12:33:5,1692300030")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := a.String(); got != "deal flop Q♠ 7♦ 5♠ at "+time.Unix(1692300030, 0).Format(time.RFC3339) {
+		t.Fatalf("unexpected line %s", got)
+	}
+}
+
+func TestActionLogVersion(t *testing.T) {
+	cases := []struct {
+		log  ActionLog
+		want int
+	}{
+		{ActionLog{}, 0},
+		{ActionLog{"G:50:100:0:0:0,1692300000"}, LogV1},
+		{ActionLog{"V:2,1692300000123", "1;G:50:100:0:0:0,1692300000123"}, LogV2},
+	}
+	for _, c := range cases {
+		if got := c.log.Version(); got != c.want {
+			t.Errorf("%v: expected version %d got %d", c.log, c.want, got)
+		}
+	}
+}
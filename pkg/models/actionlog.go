@@ -8,31 +8,55 @@ import (
 
 // Compact action codes used in action log entries.
 const (
-	ActionRaise    = "R" // player raises
-	ActionFold     = "F" // player folds
-	ActionCheck    = "C" // player checks
-	ActionAllIn    = "A" // player goes all in
-	ActionStraddle = "S" // player posts a straddle
-	ActionRunTwice = "T" // players choose run it twice/once
-	ActionBuyIn    = "B" // player buys chips before start
-	ActionJoin     = "J" // player joins the table
-	ActionQuit     = "Q" // player leaves the table
-	ActionSeat     = "H" // player selects seat for next game
+	ActionRaise      = "R" // player raises
+	ActionFold       = "F" // player folds
+	ActionCheck      = "C" // player checks
+	ActionAllIn      = "A" // player goes all in
+	ActionStraddle   = "S" // player posts a straddle
+	ActionRunTwice   = "T" // players choose run it twice/once
+	ActionBuyIn      = "B" // player buys chips for the first time in the game
+	ActionRebuy      = "Y" // player without chips buys in again
+	ActionTopUp      = "V" // player adds chips to their stack
+	ActionJoin       = "J" // player joins the table
+	ActionQuit       = "Q" // player leaves the table
+	ActionSeat       = "H" // player selects seat for next game
+	ActionAnte       = "N" // player posts an ante
+	ActionSmallBlind = "L" // player posts the small blind
+	ActionBigBlind   = "G" // player posts the big blind
+	ActionUncalled   = "U" // uncalled part of a bet returned to the player
+	ActionWin        = "W" // player wins chips from a pot
+	ActionFee        = "X" // player pays the house fee for a buy-in
+	ActionTimeBank   = "O" // player adds milliseconds from the time bank to the clock
+	ActionSitOut     = "P" // player sits out with the blinds missed so far
+	ActionSitIn      = "I" // player sits back in, 1 to post missed blinds
+	ActionDeadBlind  = "M" // player posts a missed small blind as a dead bet
 )
 
 // ActionWords maps short action codes to fully spelled words used
 // when formatting a game's action log.
 var ActionWords = map[string]string{
-	ActionRaise:    "raise",
-	ActionFold:     "fold",
-	ActionCheck:    "check",
-	ActionAllIn:    "all-in",
-	ActionStraddle: "straddle",
-	ActionRunTwice: "run-twice",
-	ActionBuyIn:    "buy-in",
-	ActionJoin:     "join",
-	ActionQuit:     "quit",
-	ActionSeat:     "seat",
+	ActionRaise:      "raise",
+	ActionFold:       "fold",
+	ActionCheck:      "check",
+	ActionAllIn:      "all-in",
+	ActionStraddle:   "straddle",
+	ActionRunTwice:   "run-twice",
+	ActionBuyIn:      "buy-in",
+	ActionRebuy:      "rebuy",
+	ActionTopUp:      "top-up",
+	ActionJoin:       "join",
+	ActionQuit:       "quit",
+	ActionSeat:       "seat",
+	ActionAnte:       "ante",
+	ActionSmallBlind: "small-blind",
+	ActionBigBlind:   "big-blind",
+	ActionUncalled:   "uncalled",
+	ActionWin:        "win",
+	ActionFee:        "fee",
+	ActionTimeBank:   "time-bank",
+	ActionSitOut:     "sit-out",
+	ActionSitIn:      "sit-in",
+	ActionDeadBlind:  "dead-blind",
 }
 
 // ActionToWord returns a human readable word for the given action
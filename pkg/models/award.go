@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// HandOrder returns the players dealt into the current hand starting with
+// the first seat left of the button and ending with the button.
+func (g *Game) HandOrder() []uuid.UUID {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	n := len(g.inHand)
+	order := make([]uuid.UUID, 0, n)
+	button := g.buttonNoLock()
+	for k := 1; k <= n; k++ {
+		order = append(order, g.inHand[(button+k)%n])
+	}
+	return order
+}
+
+// AwardPots pays out the pots of a hand that reached showdown. awards holds
+// one entry per pot returned by Pots, mapping each winner to the chips won
+// from that pot. Every pot less its rake must be paid out in full to
+// eligible players. Each payment is logged with the W code, followed by a
+// K entry with the rake of the hand.
+func (g *Game) AwardPots(awards []map[uuid.UUID]int64) error {
+	g.mu.Lock()
+	defer g.unlock()
+	if !g.inRound || g.CurrentStreet != StreetShowdown {
+		return errors.New("hand not at showdown")
+	}
+	pots := g.potsNoLock()
+	if len(awards) != len(pots) {
+		return fmt.Errorf("expected awards for %d pots got %d", len(pots), len(awards))
+	}
+	for i, pot := range pots {
+		eligible := make(map[uuid.UUID]bool)
+		for _, pid := range pot.Eligible {
+			eligible[pid] = true
+		}
+		var total int64
+		for pid, amount := range awards[i] {
+			if !eligible[pid] {
+				return fmt.Errorf("player %s not eligible for pot %d", pid, i)
+			}
+			if amount < 0 {
+				return fmt.Errorf("negative award for player %s", pid)
+			}
+			total += amount
+		}
+		if total != pot.Amount-pot.Rake {
+			return fmt.Errorf("pot %d holds %d after %d rake but %d awarded", i, pot.Amount-pot.Rake, pot.Rake, total)
+		}
+	}
+	var raked int64
+	for i, pot := range pots {
+		raked += pot.Rake
+		// pay out in seat order so the log does not depend on map order
+		for _, pid := range pot.Eligible {
+			amount := awards[i][pid]
+			if amount == 0 {
+				continue
+			}
+			if _, ok := g.Stacks[pid]; ok {
+				g.Stacks[pid] += amount
+			}
+			g.logActionNoLock(pid, ActionWin, amount)
+		}
+	}
+	if raked > 0 {
+		g.raked += raked
+		g.logNoLock(Action{Kind: EntryRake, Amount: raked, Time: g.nowNoLock()})
+	}
+	g.contributed = make(map[uuid.UUID]int64)
+	return nil
+}
+
+// Balances returns every player's current stack, or the chips they left
+// with after quitting, minus the chips they bought in for and the fees they
+// paid.
+func (g *Game) Balances() map[uuid.UUID]int64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.balancesNoLock()
+}
+
+func (g *Game) balancesNoLock() map[uuid.UUID]int64 {
+	balances := make(map[uuid.UUID]int64)
+	for _, b := range g.BuyIns {
+		balances[b.PlayerID] -= b.Amount + b.Fee
+	}
+	for pid, stack := range g.Stacks {
+		balances[pid] += stack
+	}
+	for pid, chips := range g.cashedOut {
+		balances[pid] += chips
+	}
+	return balances
+}
+
+// HouseBalance returns the rake and fees the house collected in the game.
+func (g *Game) HouseBalance() int64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.houseBalanceNoLock()
+}
+
+func (g *Game) houseBalanceNoLock() int64 {
+	total := g.raked
+	for _, b := range g.BuyIns {
+		total += b.Fee
+	}
+	return total
+}
+
+// SetLedgers replaces the ledger rows of the game. End replaces them with
+// the settled ledger.
+func (g *Game) SetLedgers(ledgers []Ledger) {
+	g.mu.Lock()
+	defer g.unlock()
+	g.Ledgers = ledgers
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAwardPotsValidation(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500)
+	g.DealHands()
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[0]: 150}}); err == nil {
+		t.Fatal("expected error awarding before showdown")
+	}
+	if err := g.AddAction(players[0], ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[0]: 100}}); err == nil {
+		t.Fatal("expected error awarding a folded player")
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[1]: 60}}); err == nil {
+		t.Fatal("expected error awarding less than the pot")
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[1]: 100}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if g.Stacks[players[1]] != 550 || len(g.Pots()) != 0 {
+		t.Fatalf("unexpected stack %d pots %v", g.Stacks[players[1]], g.Pots())
+	}
+	balances := g.Balances()
+	if balances[players[0]] != -50 || balances[players[1]] != 50 {
+		t.Fatalf("unexpected balances %v", balances)
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Straddle volunteers a player to post a straddle of twice the big blind in
+// the next hand. The straddle is only posted when the player is seated
+// directly left of the big blind and at least three players are dealt in.
+func (g *Game) Straddle(playerID uuid.UUID) error {
+	g.mu.Lock()
+	defer g.unlock()
+	if !g.AllowStraddle {
+		return errors.New("straddle not allowed")
+	}
+	if _, ok := g.Stacks[playerID]; !ok {
+		return fmt.Errorf("unknown player %s", playerID)
+	}
+	if g.straddlers == nil {
+		g.straddlers = make(map[uuid.UUID]bool)
+	}
+	g.straddlers[playerID] = true
+	return nil
+}
+
+// postForcedBetsNoLock posts the antes, blinds, any requested straddle and
+// the missed blinds of returning players for a new hand in the seats chosen
+// by moveButtonNoLock. Players who cannot cover a forced bet post what they
+// have and are all-in. The caller must hold the mutex.
+func (g *Game) postForcedBetsNoLock() {
+	n := len(g.inHand)
+	if n < 2 {
+		return
+	}
+	sb := g.seatIndexNoLock(g.SmallBlindSeat)
+	bb := g.seatIndexNoLock(g.BigBlindSeat)
+	g.lastBlind = bb
+
+	if g.Ante > 0 {
+		for _, pid := range g.inHand {
+			g.postNoLock(pid, ActionAnte, g.Ante, false)
+		}
+	}
+	// a dead small blind is not posted
+	if g.SmallBlind > 0 && sb >= 0 {
+		g.postNoLock(g.inHand[sb], ActionSmallBlind, g.SmallBlind, true)
+	}
+	if g.BigBlind > 0 {
+		g.postNoLock(g.inHand[bb], ActionBigBlind, g.BigBlind, true)
+	}
+	if n > 2 && g.AllowStraddle && g.BigBlind > 0 {
+		utg := (bb + 1) % n
+		if pid := g.inHand[utg]; g.straddlers[pid] && g.Stacks[pid] > 0 {
+			g.postNoLock(pid, ActionStraddle, 2*g.BigBlind, true)
+			g.lastBlind = utg
+		}
+	}
+	g.straddlers = make(map[uuid.UUID]bool)
+	g.postMissedNoLock()
+}
+
+// postNoLock moves a forced bet from the player's stack and logs it. Live
+// bets count towards the player's bet on the street while antes do not.
+// A big blind or straddle posted short by an all-in player still sets the
+// bet to call and the minimum raise at its full size. The caller must hold
+// the mutex.
+func (g *Game) postNoLock(playerID uuid.UUID, code string, amount int64, live bool) {
+	posted := amount
+	if stack := g.Stacks[playerID]; posted > stack {
+		posted = stack
+	}
+	if posted <= 0 {
+		return
+	}
+	g.Stacks[playerID] -= posted
+	g.contributed[playerID] += posted
+	if live {
+		g.currentBets[playerID] += posted
+		if code == ActionSmallBlind {
+			amount = posted
+		}
+		g.betRound.Post(g.currentBets[playerID] + amount - posted)
+	}
+	g.logActionNoLock(playerID, code, posted)
+}
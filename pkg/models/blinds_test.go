@@ -0,0 +1,152 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPostBlindsAndAntes(t *testing.T) {
+	g := NewGame(uuid.New(), 3)
+	g.Ante = 10
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 1000); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	want := []int64{990, 940, 890}
+	for i, p := range players {
+		if g.Stacks[p] != want[i] {
+			t.Errorf("player %d stack %d want %d", i, g.Stacks[p], want[i])
+		}
+	}
+	var codes []string
+	for _, entry := range g.ActionLog[6:] {
+		a, err := ParseAction(entry)
+		if err != nil {
+			t.Fatalf("parse %s: %v", entry, err)
+		}
+		codes = append(codes, a.Code)
+	}
+	if got := strings.Join(codes, ""); got != "NNNLG" {
+		t.Fatalf("unexpected forced bet codes %s", got)
+	}
+	g.DealHands()
+	if g.ActionOn() != players[0] {
+		t.Fatal("button should act first three-handed")
+	}
+}
+
+func TestPostBlindsShortStack(t *testing.T) {
+	g := NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	p1, p2 := uuid.New(), uuid.New()
+	if err := g.BuyIn(p1, 500); err != nil {
+		t.Fatalf("buyin p1: %v", err)
+	}
+	if err := g.BuyIn(p2, 40); err != nil {
+		t.Fatalf("buyin p2: %v", err)
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if g.Stacks[p2] != 0 {
+		t.Fatalf("short big blind should be all-in, has %d", g.Stacks[p2])
+	}
+	bb, err := ParseAction(g.ActionLog[len(g.ActionLog)-2])
+	if err != nil || bb.Player != p2.String() || bb.Code != ActionBigBlind || bb.Amount != 40 {
+		t.Fatalf("expected partial big blind entry got %s", g.ActionLog[len(g.ActionLog)-2])
+	}
+	// the small blind already covers the all-in big blind and gets the
+	// uncalled part back
+	if !g.BettingClosed() {
+		t.Fatal("no betting expected against an all-in big blind")
+	}
+	if g.Stacks[p1] != 460 {
+		t.Fatalf("expected uncalled small blind returned, stack %d", g.Stacks[p1])
+	}
+}
+
+func TestShortBigBlindSetsFullBet(t *testing.T) {
+	g, players := newStartedGame(t, func(g *Game, _ []uuid.UUID) {
+		g.MinBuyIn = 0
+	}, 1000, 1000, 30)
+	if g.Stacks[players[2]] != 0 {
+		t.Fatalf("short big blind should be all-in, has %d", g.Stacks[players[2]])
+	}
+	g.DealHands()
+	if g.ActionOn() != players[0] {
+		t.Fatal("button should act first three-handed")
+	}
+	// the all-in big blind of 30 still has to be called at 100
+	var call, raise *LegalAction
+	for _, a := range g.LegalActions() {
+		a := a
+		switch a.Code {
+		case ActionCheck:
+			call = &a
+		case ActionRaise:
+			raise = &a
+		}
+	}
+	if call == nil || call.Min != 100 || call.Max != 100 {
+		t.Fatalf("expected call of 100 got %+v", call)
+	}
+	if raise == nil || raise.Min != 200 {
+		t.Fatalf("expected minimum raise to 200 got %+v", raise)
+	}
+	if err := g.AddAction(players[0], ActionCheck, 150); err == nil {
+		t.Fatal("expected error calling 150 into a bet of 100")
+	}
+	if err := g.AddAction(players[0], ActionCheck, 100); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 100); err != nil {
+		t.Fatalf("small blind call: %v", err)
+	}
+	if !g.BettingClosed() {
+		t.Fatal("betting should close once the big blind is called")
+	}
+}
+
+func TestPostStraddle(t *testing.T) {
+	g, players := newStartedGame(t, nil, 1000, 1000, 1000, 1000)
+	g.AllowStraddle = true
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	// the button moves to player 2 so player 1 sits left of the big blind
+	if err := g.Straddle(players[0]); err != nil {
+		t.Fatalf("straddle: %v", err)
+	}
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	if g.Stacks[players[0]] != 800 {
+		t.Fatalf("expected straddle of 200 got stack %d", g.Stacks[players[0]])
+	}
+	g.DealHands()
+	if g.ActionOn() != players[1] {
+		t.Fatal("action should start left of the straddle")
+	}
+	for _, a := range g.LegalActions() {
+		if a.Code == ActionCheck && a.Max != 200 {
+			t.Fatalf("expected call of 200 got %d", a.Max)
+		}
+	}
+}
+
+func TestStraddleNotAllowed(t *testing.T) {
+	g, players := newStartedGame(t, nil, 1000, 1000, 1000)
+	if err := g.Straddle(players[0]); err == nil {
+		t.Fatal("expected error when straddles are disabled")
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+// moveButtonNoLock places the dealer button and the blinds for a new hand
+// by seat number. The big blind moves to the next player dealt in after the
+// previous big blind, the small blind to the previous big blind's seat and
+// the button to the previous small blind's seat. When players leave those
+// seats may be empty: the small blind is then not posted and the button is
+// dead, so nobody skips or pays the big blind twice. The first hand puts the
+// button on the first player at or after CurrentDealer and heads-up the
+// button posts the small blind. The caller must hold the mutex.
+func (g *Game) moveButtonNoLock() {
+	g.button = 0
+	n := len(g.inHand)
+	if n < 2 {
+		return
+	}
+	switch {
+	case g.BigBlindSeat == 0:
+		g.CurrentDealer = g.nextSeatNoLock(g.CurrentDealer - 1)
+		g.SmallBlindSeat = g.nextSeatNoLock(g.CurrentDealer)
+		if n == 2 {
+			g.SmallBlindSeat = g.CurrentDealer
+		}
+		g.BigBlindSeat = g.nextSeatNoLock(g.SmallBlindSeat)
+	case n == 2:
+		g.BigBlindSeat = g.nextSeatNoLock(g.BigBlindSeat)
+		g.SmallBlindSeat = g.nextSeatNoLock(g.BigBlindSeat)
+		g.CurrentDealer = g.SmallBlindSeat
+	default:
+		g.CurrentDealer = g.SmallBlindSeat
+		g.SmallBlindSeat = g.BigBlindSeat
+		g.BigBlindSeat = g.nextSeatNoLock(g.BigBlindSeat)
+		if g.CurrentDealer == g.BigBlindSeat || g.SmallBlindSeat == g.BigBlindSeat {
+			// a player sat down between the blinds; keep the button
+			// right of the small blind
+			g.CurrentDealer = g.prevSeatNoLock(g.SmallBlindSeat)
+		}
+	}
+	// the button index is the last player dealt in at or before the button
+	// seat, so action after the button skips a dead button's empty seat
+	g.button = n - 1
+	for i, pid := range g.inHand {
+		if g.Seats[pid] <= g.CurrentDealer {
+			g.button = i
+		}
+	}
+}
+
+// nextSeatNoLock returns the seat of the first player dealt in after seat,
+// wrapping around the table. The caller must hold the mutex.
+func (g *Game) nextSeatNoLock(seat int) int {
+	for _, pid := range g.inHand {
+		if g.Seats[pid] > seat {
+			return g.Seats[pid]
+		}
+	}
+	return g.Seats[g.inHand[0]]
+}
+
+// prevSeatNoLock returns the seat of the last player dealt in before seat,
+// wrapping around the table. The caller must hold the mutex.
+func (g *Game) prevSeatNoLock(seat int) int {
+	for i := len(g.inHand) - 1; i >= 0; i-- {
+		if s := g.Seats[g.inHand[i]]; s < seat {
+			return s
+		}
+	}
+	return g.Seats[g.inHand[len(g.inHand)-1]]
+}
+
+// seatIndexNoLock returns the index of the player dealt in at seat or -1
+// when the seat is empty. The caller must hold the mutex.
+func (g *Game) seatIndexNoLock(seat int) int {
+	if seat <= 0 {
+		return -1
+	}
+	for i, pid := range g.inHand {
+		if g.Seats[pid] == seat {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func nextRound(t *testing.T, g *Game) {
+	t.Helper()
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+}
+
+func checkPositions(t *testing.T, g *Game, button, sb, bb int) {
+	t.Helper()
+	if g.CurrentDealer != button || g.SmallBlindSeat != sb || g.BigBlindSeat != bb {
+		t.Fatalf("expected button %d sb %d bb %d got %d %d %d", button, sb, bb, g.CurrentDealer, g.SmallBlindSeat, g.BigBlindSeat)
+	}
+}
+
+func TestButtonMovesBySeat(t *testing.T) {
+	g, _ := newStartedGame(t, withSeats(2, 5, 7), 1000, 1000, 1000)
+	checkPositions(t, g, 2, 5, 7)
+	nextRound(t, g)
+	checkPositions(t, g, 5, 7, 2)
+	nextRound(t, g)
+	checkPositions(t, g, 7, 2, 5)
+}
+
+func TestDeadButton(t *testing.T) {
+	g, players := newStartedGame(t, withSeats(1, 2, 3, 4), 1000, 1000, 1000, 1000)
+	checkPositions(t, g, 1, 2, 3)
+	// the small blind leaves, so the button is dead on the empty seat 2
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	if err := g.Quit(players[1]); err != nil {
+		t.Fatalf("quit: %v", err)
+	}
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	checkPositions(t, g, 2, 3, 4)
+	sb, _ := ParseAction(g.ActionLog[len(g.ActionLog)-2])
+	bb, _ := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if sb.Player != players[2].String() || sb.Code != ActionSmallBlind || bb.Player != players[3].String() || bb.Code != ActionBigBlind {
+		t.Fatalf("expected seat 3 to post the small blind and seat 4 the big blind got %s %s", sb, bb)
+	}
+	g.DealHands()
+	for i := 0; i < 3; i++ {
+		checkOrCall(t, g)
+	}
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	if g.ActionOn() != players[2] {
+		t.Fatal("expected the small blind to act first after a dead button")
+	}
+}
+
+func TestDeadSmallBlind(t *testing.T) {
+	g, players := newStartedGame(t, withSeats(1, 2, 3, 4), 1000, 1000, 1000, 1000)
+	// the big blind leaves, so nobody posts the small blind next hand
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	if err := g.Quit(players[2]); err != nil {
+		t.Fatalf("quit: %v", err)
+	}
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	checkPositions(t, g, 2, 3, 4)
+	if g.PotTotal() != 100 {
+		t.Fatalf("expected only the big blind posted got %d", g.PotTotal())
+	}
+	g.DealHands()
+	if g.ActionOn() != players[0] {
+		t.Fatal("expected action left of the big blind")
+	}
+}
+
+func TestTableNextGame(t *testing.T) {
+	table := &Table{ID: uuid.New()}
+	g := NewGame(table.ID, 3)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.MaxBuyIn = 1000
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 1000); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if _, err := table.NextGame(g); err == nil {
+		t.Fatal("expected error before the game ended")
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	// the button and the small blind fold to the big blind
+	g.DealHands()
+	for i := 0; i < 2; i++ {
+		if err := g.AddAction(g.ActionOn(), ActionFold, 0); err != nil {
+			t.Fatalf("fold: %v", err)
+		}
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[2]: 100}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if err := g.ChooseSeat(players[0], 6); err != nil {
+		t.Fatalf("choose seat: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+
+	next, err := table.NextGame(g)
+	if err != nil {
+		t.Fatalf("next game: %v", err)
+	}
+	want := map[uuid.UUID]int{players[0]: 6, players[1]: 2, players[2]: 3}
+	for p, seat := range want {
+		if next.Seats[p] != seat {
+			t.Errorf("expected seat %d got %d", seat, next.Seats[p])
+		}
+	}
+	if next.PersonCount != 3 || next.Stacks[players[2]] != 1050 {
+		t.Fatalf("expected stacks to carry over got %v", next.Stacks)
+	}
+	// the carried stack above the maximum buy-in does not block the start
+	if err := next.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	checkPositions(t, next, 2, 3, 6)
+}
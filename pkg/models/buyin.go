@@ -8,15 +8,33 @@ import (
 	"github.com/google/uuid"
 )
 
-// BuyIn records the starting chip amount a player brings to a game.
+// BuyIn records chips a player brings to a game. Carried buy-ins hold a
+// stack brought over from the previous game at the table and are not
+// checked against the buy-in limits. Rebuys are made by players without
+// chips after their first buy-in and top-ups add to a stack. Fee is paid
+// to the house on top of the chips.
 type BuyIn struct {
 	PlayerID uuid.UUID `json:"player_id"`
 	Amount   int64     `json:"amount"`
+	Carried  bool      `json:"carried,omitempty"`
+	Rebuy    bool      `json:"rebuy,omitempty"`
+	TopUp    bool      `json:"top_up,omitempty"`
+	Fee      int64     `json:"fee,omitempty"`
 }
 
 // BuyInList is a JSON serializable slice of BuyIns.
 type BuyInList []BuyIn
 
+// Players returns the number of players who bought in; top-ups and rebuys
+// add entries for a player already counted.
+func (b BuyInList) Players() int {
+	players := make(map[uuid.UUID]bool)
+	for _, in := range b {
+		players[in.PlayerID] = true
+	}
+	return len(players)
+}
+
 // Value implements driver.Valuer so BuyInList can be persisted by GORM.
 func (b BuyInList) Value() (driver.Value, error) {
 	data, err := json.Marshal(b)
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// bustHand starts and plays a hand in which loser goes all-in and winner
+// calls and takes the pot. Any other player folds.
+func bustHand(t *testing.T, g *Game, loser, winner uuid.UUID) {
+	t.Helper()
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	g.DealHands()
+	for g.ActionOn() != uuid.Nil {
+		switch pid := g.ActionOn(); pid {
+		case loser:
+			for _, a := range g.LegalActions() {
+				if a.Code == ActionAllIn {
+					if err := g.AddAction(pid, ActionAllIn, a.Max); err != nil {
+						t.Fatalf("all-in: %v", err)
+					}
+				}
+			}
+		case winner:
+			checkOrCall(t, g)
+		default:
+			if err := g.AddAction(pid, ActionFold, 0); err != nil {
+				t.Fatalf("fold: %v", err)
+			}
+		}
+	}
+	if _, err := g.RunOut(); err != nil {
+		t.Fatalf("run out: %v", err)
+	}
+	awards := []map[uuid.UUID]int64{}
+	for _, pot := range g.Pots() {
+		awards = append(awards, map[uuid.UUID]int64{winner: pot.Amount - pot.Rake})
+	}
+	if err := g.AwardPots(awards); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+}
+
+func TestRebuyAndTopUp(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 1000, 300)
+	g.MaxRebuys = 1
+	if err := g.BuyIn(players[2], 100); err == nil {
+		t.Fatal("expected top-up during the hand to fail")
+	}
+	late := uuid.New()
+	if err := g.Join(late); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := g.BuyIn(late, 200); err != nil {
+		t.Fatalf("buy-in of a player not dealt in: %v", err)
+	}
+	if err := g.BuyIn(late, 100); err != nil {
+		t.Fatalf("top-up of a player not dealt in: %v", err)
+	}
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	if err := g.Quit(late); err != nil {
+		t.Fatalf("quit: %v", err)
+	}
+
+	bustHand(t, g, players[0], players[1])
+	if g.Stacks[players[0]] != 0 {
+		t.Fatalf("expected player 1 to bust got %d", g.Stacks[players[0]])
+	}
+	if err := g.BuyIn(players[0], 50); err == nil {
+		t.Fatal("expected rebuy below the minimum to fail")
+	}
+	if err := g.BuyIn(players[0], 500); err != nil {
+		t.Fatalf("rebuy: %v", err)
+	}
+	if err := g.BuyIn(players[2], g.MaxBuyIn); err == nil {
+		t.Fatal("expected top-up above the maximum to fail")
+	}
+	topUp := g.MaxBuyIn - g.Stacks[players[2]]
+	if err := g.BuyIn(players[2], topUp); err != nil {
+		t.Fatalf("top-up: %v", err)
+	}
+
+	bustHand(t, g, players[0], players[1])
+	if err := g.BuyIn(players[0], 500); err == nil {
+		t.Fatal("expected rebuy beyond MaxRebuys to fail")
+	}
+
+	codes := map[string]int{}
+	for _, entry := range g.ActionLog {
+		if a, err := ParseAction(entry); err == nil && a.Kind == EntryPlayer {
+			codes[a.Code]++
+		}
+	}
+	if codes[ActionBuyIn] != 4 || codes[ActionRebuy] != 1 || codes[ActionTopUp] != 2 {
+		t.Fatalf("unexpected buy-in entries %v", codes)
+	}
+
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	want := []Ledger{
+		{PlayerID: players[0], BuyIn: 500, Rebuys: 500},
+		{PlayerID: players[1], BuyIn: 1000},
+		{PlayerID: players[2], BuyIn: 300, TopUps: topUp},
+		{PlayerID: late, BuyIn: 200, TopUps: 100},
+	}
+	if len(g.Ledgers) != len(want) {
+		t.Fatalf("expected a ledger row per player got %+v", g.Ledgers)
+	}
+	for i, w := range want {
+		l := g.Ledgers[i]
+		if l.PlayerID != w.PlayerID || l.BuyIn != w.BuyIn || l.Rebuys != w.Rebuys || l.TopUps != w.TopUps {
+			t.Errorf("ledger %d got %+v want %+v", i, l, w)
+		}
+	}
+	if _, err := Replay(g.ActionLog, g.CardSequence); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+}
+
+func TestTopUpBeforeStart(t *testing.T) {
+	g := NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.MinBuyIn = 200
+	g.MaxBuyIn = 1000
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 500); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	// a top-up below the minimum buy-in is fine as long as the stack stays
+	// within the maximum
+	if err := g.BuyIn(players[0], 100); err != nil {
+		t.Fatalf("top-up: %v", err)
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start after a top-up: %v", err)
+	}
+	if g.Stacks[players[0]]+g.Stacks[players[1]]+g.PotTotal() != 1100 {
+		t.Fatalf("unexpected stacks %v", g.Stacks)
+	}
+	replayed, err := Replay(g.ActionLog, g.CardSequence)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.PersonCount != 2 || replayed.Stacks[players[0]] != g.Stacks[players[0]] {
+		t.Fatalf("unexpected replayed game: %d players, stacks %v", replayed.PersonCount, replayed.Stacks)
+	}
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTimeBank is returned when a player asks for more time than is left in
+// their time bank.
+var ErrTimeBank = errors.New("not enough time in the time bank")
+
+// Clock tells a game the time. The game reads it for its log entries and
+// the action clock, so tests can move time forward with a ManualClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the system time. It is used when Game.Clock is nil.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock is a Clock that only moves when it is advanced.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a clock standing at start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the time the clock stands at.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// turnClock tracks the time of the player to act and the time bank every
+// player used in the game.
+type turnClock struct {
+	started  time.Time                   // when the action moved to the player
+	extra    time.Duration               // time bank added to the current turn
+	bankUsed map[uuid.UUID]time.Duration // time bank used per player
+}
+
+// nowNoLock returns the time of the game's clock. The caller must hold the
+// mutex.
+func (g *Game) nowNoLock() time.Time {
+	if g.Clock == nil {
+		return time.Now()
+	}
+	return g.Clock.Now()
+}
+
+// startTurnNoLock starts the decision time of the player the action moved
+// to. The caller must hold the mutex.
+func (g *Game) startTurnNoLock() {
+	g.clock.started = g.nowNoLock()
+	g.clock.extra = 0
+}
+
+// Deadline returns when the decision time of the player to act runs out,
+// including the time bank the player added. It returns the zero time when
+// the game has no action clock or no action is pending.
+func (g *Game) Deadline() time.Time {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.deadlineNoLock()
+}
+
+func (g *Game) deadlineNoLock() time.Time {
+	if g.DecisionTime <= 0 || g.legalActionsNoLock() == nil {
+		return time.Time{}
+	}
+	return g.clock.started.Add(g.DecisionTime + g.clock.extra)
+}
+
+// TimeBankLeft returns the time the player may still add to their clock in
+// this game.
+func (g *Game) TimeBankLeft(playerID uuid.UUID) time.Duration {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.TimeBank - g.clock.bankUsed[playerID]
+}
+
+// UseTimeBank adds d from the player's time bank to the decision time of
+// the current turn. Only the player to act may use their time bank, and
+// only while their clock is running. The extension is logged with the O
+// code and the milliseconds added.
+func (g *Game) UseTimeBank(playerID uuid.UUID, d time.Duration) error {
+	g.mu.Lock()
+	defer g.unlock()
+	if g.DecisionTime <= 0 {
+		return errors.New("game has no action clock")
+	}
+	if g.legalActionsNoLock() == nil {
+		return errors.New("no action pending")
+	}
+	if on := g.actionOnNoLock(); on != playerID {
+		return &TurnError{PlayerID: playerID, ActionOn: on}
+	}
+	if d <= 0 {
+		return errors.New("time bank extension must be positive")
+	}
+	if left := g.TimeBank - g.clock.bankUsed[playerID]; d > left {
+		return fmt.Errorf("%w: %s left", ErrTimeBank, left)
+	}
+	if g.clock.bankUsed == nil {
+		g.clock.bankUsed = make(map[uuid.UUID]time.Duration)
+	}
+	g.clock.bankUsed[playerID] += d
+	g.clock.extra += d
+	g.logActionNoLock(playerID, ActionTimeBank, d.Milliseconds())
+	return nil
+}
+
+// Tick acts for the player to act once their time has run out: the player
+// checks when nothing is to call and folds otherwise. The timed out player
+// is returned, or uuid.Nil when the clock has not run out or the game has
+// no action clock. Tables call Tick regularly to keep the game moving.
+func (g *Game) Tick() (uuid.UUID, error) {
+	g.mu.Lock()
+	defer g.unlock()
+	deadline := g.deadlineNoLock()
+	if deadline.IsZero() || g.nowNoLock().Before(deadline) {
+		return uuid.Nil, nil
+	}
+	pid := g.actionOnNoLock()
+	code := ActionFold
+	for _, a := range g.legalActionsNoLock() {
+		if a.Code == ActionCheck && a.Max == 0 {
+			code = ActionCheck
+		}
+	}
+	if err := g.addActionNoLock(pid, code, 0); err != nil {
+		return uuid.Nil, err
+	}
+	return pid, nil
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// withClock runs a 30 second action clock and a 60 second time bank on
+// the manual clock.
+func withClock(clock *ManualClock) gameOption {
+	return func(g *Game, _ []uuid.UUID) {
+		g.Clock = clock
+		g.DecisionTime = 30 * time.Second
+		g.TimeBank = time.Minute
+	}
+}
+
+func TestActionClockFolds(t *testing.T) {
+	clock := NewManualClock(time.UnixMilli(1692300000000))
+	g, _ := newStartedGame(t, withClock(clock), 500, 500)
+	g.DealHands()
+	on := g.ActionOn()
+	if want := clock.Now().Add(30 * time.Second); !g.Deadline().Equal(want) {
+		t.Fatalf("deadline %v want %v", g.Deadline(), want)
+	}
+	clock.Advance(29 * time.Second)
+	if pid, err := g.Tick(); err != nil || pid != uuid.Nil {
+		t.Fatalf("expected the clock to keep running got %s %v", pid, err)
+	}
+	clock.Advance(time.Second)
+	if pid, err := g.Tick(); err != nil || pid != on {
+		t.Fatalf("expected %s to time out got %s %v", on, pid, err)
+	}
+	// the fold is followed by the uncalled part of the big blind
+	fold, err := ParseAction(g.ActionLog[len(g.ActionLog)-2])
+	if err != nil || fold.Code != ActionFold || !fold.Time.Equal(clock.Now()) {
+		t.Fatalf("expected a fold at the clock's time got %s", g.ActionLog[len(g.ActionLog)-2])
+	}
+	if !g.Deadline().IsZero() {
+		t.Fatalf("expected no deadline once betting is over got %v", g.Deadline())
+	}
+}
+
+func TestActionClockChecks(t *testing.T) {
+	clock := NewManualClock(time.UnixMilli(1692300000000))
+	g, _ := newStartedGame(t, withClock(clock), 500, 500)
+	g.DealHands()
+	checkOrCall(t, g)
+	// the big blind may check
+	on := g.ActionOn()
+	clock.Advance(time.Minute)
+	if pid, err := g.Tick(); err != nil || pid != on {
+		t.Fatalf("expected %s to time out got %s %v", on, pid, err)
+	}
+	last, err := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if err != nil || last.Code != ActionCheck || last.Amount != 0 {
+		t.Fatalf("expected a check got %s", g.ActionLog[len(g.ActionLog)-1])
+	}
+}
+
+func TestTimeBank(t *testing.T) {
+	clock := NewManualClock(time.UnixMilli(1692300000000))
+	g, players := newStartedGame(t, withClock(clock), 500, 500)
+	g.DealHands()
+	on := g.ActionOn()
+	other := players[0]
+	if other == on {
+		other = players[1]
+	}
+	var turnErr *TurnError
+	if err := g.UseTimeBank(other, time.Second); !errors.As(err, &turnErr) {
+		t.Fatalf("expected a turn error got %v", err)
+	}
+	if err := g.UseTimeBank(on, 2*time.Minute); !errors.Is(err, ErrTimeBank) {
+		t.Fatalf("expected ErrTimeBank got %v", err)
+	}
+	clock.Advance(25 * time.Second)
+	if err := g.UseTimeBank(on, 20*time.Second); err != nil {
+		t.Fatalf("time bank: %v", err)
+	}
+	last, err := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if err != nil || last.Code != ActionTimeBank || last.Amount != 20000 {
+		t.Fatalf("expected a time bank entry got %s", g.ActionLog[len(g.ActionLog)-1])
+	}
+	if g.TimeBankLeft(on) != 40*time.Second {
+		t.Fatalf("expected 40s left got %s", g.TimeBankLeft(on))
+	}
+	clock.Advance(20 * time.Second)
+	if pid, err := g.Tick(); err != nil || pid != uuid.Nil {
+		t.Fatalf("expected the extended clock to keep running got %s %v", pid, err)
+	}
+	checkOrCall(t, g)
+
+	replayed, err := Replay(g.ActionLog, g.CardSequence)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.DecisionTime != g.DecisionTime || replayed.TimeBankLeft(on) != 40*time.Second {
+		t.Fatalf("replay lost the clock: %s %s", replayed.DecisionTime, replayed.TimeBankLeft(on))
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrDeckExhausted is returned when a deal needs more cards than are left
+// in the deck.
+var ErrDeckExhausted = errors.New("deck exhausted")
+
+// Deal modes recorded in the start entry. DealStandard deals hole cards one
+// at a time around the table starting left of the button and burns a card
+// before every street. DealPerHand deals the same way from a deck shuffled
+// afresh for every hand. Games logged before the mode was recorded used
+// DealLegacy: two consecutive cards per player and no burn cards. Games
+// dealt in DealLegacy and DealStandard mode deal every hand from the rest
+// of a single deck.
+const (
+	DealLegacy   = ""
+	DealStandard = "std"
+	DealPerHand  = "hand"
+)
+
+// DealtCard records a card taken from the deck. Player is uuid.Nil for
+// community and burn cards.
+type DealtCard struct {
+	Card     int
+	Position int // index of the card in the deck
+	Player   uuid.UUID
+	Burn     bool
+}
+
+// Deck deals cards in order from a shuffled card sequence and records where
+// every card went. A deal that needs more cards than are left fails with
+// ErrDeckExhausted and takes no cards.
+type Deck struct {
+	cards IntSlice
+	next  int
+	dealt []DealtCard
+}
+
+// NewDeck returns a deck dealing cards in the given order.
+func NewDeck(cards IntSlice) *Deck {
+	return &Deck{cards: append(IntSlice{}, cards...)}
+}
+
+// Remaining returns the number of cards left in the deck.
+func (d *Deck) Remaining() int {
+	return len(d.cards) - d.next
+}
+
+// Burn discards the top card.
+func (d *Deck) Burn() error {
+	if d.Remaining() < 1 {
+		return fmt.Errorf("%w: no card to burn", ErrDeckExhausted)
+	}
+	d.take(uuid.Nil, true)
+	return nil
+}
+
+// Draw deals count community cards.
+func (d *Deck) Draw(count int) ([]int, error) {
+	return d.DealTo(uuid.Nil, count)
+}
+
+// DealTo deals count consecutive cards to a player.
+func (d *Deck) DealTo(player uuid.UUID, count int) ([]int, error) {
+	if d.Remaining() < count {
+		return nil, fmt.Errorf("%w: %d cards needed, %d left", ErrDeckExhausted, count, d.Remaining())
+	}
+	cards := make([]int, count)
+	for i := range cards {
+		cards[i] = d.take(player, false)
+	}
+	return cards, nil
+}
+
+// DealRound deals count cards to each player, one card at a time in the
+// order given. The hands are returned in the same order.
+func (d *Deck) DealRound(players []uuid.UUID, count int) ([][]int, error) {
+	if need := len(players) * count; d.Remaining() < need {
+		return nil, fmt.Errorf("%w: %d cards needed, %d left", ErrDeckExhausted, need, d.Remaining())
+	}
+	hands := make([][]int, len(players))
+	for k := 0; k < count; k++ {
+		for i, pid := range players {
+			hands[i] = append(hands[i], d.take(pid, false))
+		}
+	}
+	return hands, nil
+}
+
+// Refill continues dealing from cards, a freshly shuffled deck, leaving the
+// rest of the current deck undealt. The positions of the new cards follow
+// those of the earlier ones.
+func (d *Deck) Refill(cards IntSlice) {
+	d.cards = append(d.cards, cards...)
+	d.next = len(d.cards) - len(cards)
+}
+
+// Dealt returns a copy of the record of the cards dealt so far.
+func (d *Deck) Dealt() []DealtCard {
+	return append([]DealtCard{}, d.dealt...)
+}
+
+// take deals the top card and records it.
+func (d *Deck) take(player uuid.UUID, burn bool) int {
+	card := d.cards[d.next]
+	d.dealt = append(d.dealt, DealtCard{Card: card, Position: d.next, Player: player, Burn: burn})
+	d.next++
+	return card
+}
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDeck(t *testing.T) {
+	d := NewDeck(IntSlice{1, 2, 3, 4, 5, 6, 7})
+	p1, p2 := uuid.New(), uuid.New()
+	hands, err := d.DealRound([]uuid.UUID{p1, p2}, 2)
+	if err != nil || !reflect.DeepEqual(hands, [][]int{{1, 3}, {2, 4}}) {
+		t.Fatalf("expected cards dealt in rotation got %v: %v", hands, err)
+	}
+	if err := d.Burn(); err != nil {
+		t.Fatalf("burn: %v", err)
+	}
+	if _, err := d.Draw(3); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("expected ErrDeckExhausted got %v", err)
+	}
+	if d.Remaining() != 2 {
+		t.Fatalf("a failed deal must not take cards, %d left", d.Remaining())
+	}
+	if cards, err := d.Draw(2); err != nil || !reflect.DeepEqual(cards, []int{6, 7}) {
+		t.Fatalf("unexpected draw %v: %v", cards, err)
+	}
+	if err := d.Burn(); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("expected ErrDeckExhausted got %v", err)
+	}
+	dealt := d.Dealt()
+	if len(dealt) != 7 || dealt[0].Player != p1 || dealt[1].Player != p2 || !dealt[4].Burn || dealt[5].Player != uuid.Nil || dealt[6].Position != 6 {
+		t.Fatalf("unexpected record %+v", dealt)
+	}
+}
+
+func TestDealByPosition(t *testing.T) {
+	g, players := newStartedGame(t, nil, 1000, 1000, 1000)
+	deck := g.CardSequence
+	hands := g.DealHands()
+	// the button is on the first seat, so its left neighbour gets the
+	// first card
+	want := [][]int{{deck[2], deck[5]}, {deck[0], deck[3]}, {deck[1], deck[4]}}
+	if !reflect.DeepEqual(hands, want) {
+		t.Fatalf("expected hands %v got %v", want, hands)
+	}
+	for range players {
+		checkOrCall(t, g)
+	}
+	flop, err := g.NextStreet()
+	if err != nil || !reflect.DeepEqual(flop, []int{deck[7], deck[8], deck[9]}) {
+		t.Fatalf("expected a burn before the flop got %v: %v", flop, err)
+	}
+	dealt, err := g.DealtCards("test")
+	if err != nil || len(dealt) != 10 || !dealt[6].Burn || dealt[0].Player != players[1] {
+		t.Fatalf("unexpected record %+v: %v", dealt, err)
+	}
+}
+
+func TestLegacyDeal(t *testing.T) {
+	g := NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 1000); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	cards := IntSlice{}
+	for c := 1; c <= 52; c++ {
+		cards = append(cards, c)
+	}
+	// games logged before deal modes were recorded start without one
+	g.mu.Lock()
+	g.startNoLock(cards, &GameStart{}, time.Now())
+	g.mu.Unlock()
+	if hands := g.DealHands(); !reflect.DeepEqual(hands, [][]int{{1, 2}, {3, 4}}) {
+		t.Fatalf("expected consecutive hole cards got %v", hands)
+	}
+	checkOrCall(t, g)
+	checkOrCall(t, g)
+	if flop, err := g.NextStreet(); err != nil || !reflect.DeepEqual(flop, []int{5, 6, 7}) {
+		t.Fatalf("expected the flop without a burn got %v: %v", flop, err)
+	}
+	replayed, err := Replay(g.ActionLog, cards)
+	if err != nil || !reflect.DeepEqual(replayed.Board, g.Board) {
+		t.Fatalf("replay: %v", err)
+	}
+}
+
+func TestDealExhausted(t *testing.T) {
+	g, _ := newStartedGame(t, nil, 1000, 1000)
+	g.mu.Lock()
+	g.deck = NewDeck(g.CardSequence[:6])
+	g.mu.Unlock()
+	g.DealHands()
+	checkOrCall(t, g)
+	checkOrCall(t, g)
+	if _, err := g.NextStreet(); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("expected ErrDeckExhausted got %v", err)
+	}
+}
+
+// foldAround deals a hand in which every player folds to the last one, who
+// takes the pots, and ends the round.
+func foldAround(t *testing.T, g *Game) {
+	t.Helper()
+	if _, err := g.DealHoleCards(); err != nil {
+		t.Fatalf("deal: %v", err)
+	}
+	for g.ActionOn() != uuid.Nil {
+		if err := g.AddAction(g.ActionOn(), ActionFold, 0); err != nil {
+			t.Fatalf("fold: %v", err)
+		}
+	}
+	awards := []map[uuid.UUID]int64{}
+	for _, pot := range g.Pots() {
+		awards = append(awards, map[uuid.UUID]int64{pot.Eligible[0]: pot.Amount - pot.Rake})
+	}
+	if err := g.AwardPots(awards); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+}
+
+func TestNewDeckEveryHand(t *testing.T) {
+	f, err := NewFairShuffler()
+	if err != nil {
+		t.Fatalf("shuffler: %v", err)
+	}
+	g, _ := newStartedGame(t, withShuffler(f), 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000)
+	// a single deck covers two full-ring hands
+	const hands = 5
+	for hand := 1; hand <= hands; hand++ {
+		if hand > 1 {
+			if err := g.StartRound(); err != nil {
+				t.Fatalf("hand %d: %v", hand, err)
+			}
+		}
+		foldAround(t, g)
+	}
+	if len(g.CardSequence) != hands*52 {
+		t.Fatalf("expected a deck per hand got %d cards", len(g.CardSequence))
+	}
+	if reflect.DeepEqual(g.CardSequence[:52], g.CardSequence[52:104]) {
+		t.Fatal("expected the second hand to be shuffled afresh")
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	deck, err := VerifyShuffle(g.ActionLog)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !reflect.DeepEqual(deck, g.CardSequence) {
+		t.Fatal("expected the decks of every hand to be derived from the seeds")
+	}
+	replayed, err := Replay(g.ActionLog, g.CardSequence)
+	if err != nil || replayed.CurrentRound != hands {
+		t.Fatalf("replay: %v", err)
+	}
+	if _, err := Replay(g.ActionLog, g.CardSequence[:4*52]); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("expected a missing deck to fail the replay got %v", err)
+	}
+}
+
+func TestStartRoundWithoutCards(t *testing.T) {
+	g, players := newStartedGame(t, nil, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000)
+	// games logged with deal=std deal every hand from one deck
+	g.mu.Lock()
+	g.dealMode = DealStandard
+	g.mu.Unlock()
+	foldAround(t, g)
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("second hand: %v", err)
+	}
+	foldAround(t, g)
+	stacks := make(map[uuid.UUID]int64)
+	for _, p := range players {
+		stacks[p] = g.Stacks[p]
+	}
+	logged := len(g.ActionLog)
+	if err := g.StartRound(); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("expected ErrDeckExhausted got %v", err)
+	}
+	if len(g.ActionLog) != logged || !reflect.DeepEqual(stacks, g.Stacks) {
+		t.Fatal("expected no blinds posted for a hand that cannot be dealt")
+	}
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// EventType classifies the events a game publishes.
+type EventType int
+
+// Event types, one for every kind of action log entry.
+const (
+	EventBuyIn        EventType = iota + 1 // buy-in, rebuy, top-up or fee (B, Y, V, X)
+	EventPlayerJoined                      // player joins the table (J)
+	EventPlayerLeft                        // player quits the table (Q)
+	EventSeatChosen                        // seat picked for the next game (H)
+	EventGameStarted                       // start entry (G:)
+	EventBlindPosted                       // ante, blind, straddle or missed blind (N, L, G, S, M)
+	EventStreetDealt                       // street or extra board dealt (D:)
+	EventActionTaken                       // betting action, returned bet, run choice or time bank (R, F, C, A, U, T, O)
+	EventPotAwarded                        // pot payout or rake (W, K:)
+	EventGameEnded                         // end entry with the ledger (E:)
+	EventSatOut                            // player sits out (P)
+	EventSatIn                             // player sits back in (I)
+)
+
+var eventNames = map[EventType]string{
+	EventBuyIn:        "buy-in",
+	EventPlayerJoined: "player-joined",
+	EventPlayerLeft:   "player-left",
+	EventSeatChosen:   "seat-chosen",
+	EventGameStarted:  "game-started",
+	EventBlindPosted:  "blind-posted",
+	EventStreetDealt:  "street-dealt",
+	EventActionTaken:  "action-taken",
+	EventPotAwarded:   "pot-awarded",
+	EventGameEnded:    "game-ended",
+	EventSatOut:       "sat-out",
+	EventSatIn:        "sat-in",
+}
+
+func (t EventType) String() string {
+	if n, ok := eventNames[t]; ok {
+		return n
+	}
+	return "unknown"
+}
+
+// Event describes an entry added to a game's action log.
+type Event struct {
+	Type     EventType
+	Index    int       // index of the entry in ActionLog
+	Entry    string    // encoded entry
+	Action   Action    // decoded entry
+	PlayerID uuid.UUID // player the entry is about, uuid.Nil for game events
+}
+
+// eventType returns the event type of a log entry. The version header is
+// not published.
+func eventType(a Action) (EventType, bool) {
+	switch a.Kind {
+	case EntryStart:
+		return EventGameStarted, true
+	case EntryStreet:
+		return EventStreetDealt, true
+	case EntryEnd:
+		return EventGameEnded, true
+	case EntryRake:
+		return EventPotAwarded, true
+	case EntryVersion:
+		return 0, false
+	}
+	switch a.Code {
+	case ActionBuyIn, ActionRebuy, ActionTopUp, ActionFee:
+		return EventBuyIn, true
+	case ActionJoin:
+		return EventPlayerJoined, true
+	case ActionQuit:
+		return EventPlayerLeft, true
+	case ActionSeat:
+		return EventSeatChosen, true
+	case ActionAnte, ActionSmallBlind, ActionBigBlind, ActionStraddle, ActionDeadBlind:
+		return EventBlindPosted, true
+	case ActionSitOut:
+		return EventSatOut, true
+	case ActionSitIn:
+		return EventSatIn, true
+	case ActionWin:
+		return EventPotAwarded, true
+	}
+	return EventActionTaken, true
+}
+
+// eventBus queues the events of a game for its subscribers. Events are
+// queued while the game mutex is held, so the queue is in log order, and
+// delivered after it is released.
+type eventBus struct {
+	mu         sync.Mutex
+	next       int
+	subs       map[int]func(Event)
+	queue      []queuedEvent
+	delivering bool
+}
+
+// queuedEvent is an event waiting for delivery to the subscribers that
+// were registered when it was logged.
+type queuedEvent struct {
+	event Event
+	subs  []func(Event)
+}
+
+// Subscribe registers fn to be called with every event the game logs from
+// now on. Events are delivered one at a time in log order and without the
+// game mutex held, so fn may call back into the game; the events this
+// causes are delivered after fn returns. Delivery happens on the goroutine
+// that changed the game, or on the one already delivering events, so fn
+// should hand slow work off. The returned function cancels the
+// subscription; events already logged may still be delivered.
+func (g *Game) Subscribe(fn func(Event)) (unsubscribe func()) {
+	g.mu.Lock()
+	defer g.unlock()
+	b := &g.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[int]func(Event))
+	}
+	id := b.next
+	b.next++
+	b.subs[id] = fn
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+// queueEventNoLock queues the event of the log entry just added. The caller
+// must hold the mutex.
+func (g *Game) queueEventNoLock(a Action, playerID uuid.UUID) {
+	t, ok := eventType(a)
+	if !ok {
+		return
+	}
+	b := &g.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) == 0 {
+		return
+	}
+	// deliver in subscription order
+	subs := make([]func(Event), 0, len(b.subs))
+	for id := 0; id < b.next; id++ {
+		if fn, ok := b.subs[id]; ok {
+			subs = append(subs, fn)
+		}
+	}
+	index := len(g.ActionLog) - 1
+	e := Event{Type: t, Index: index, Entry: g.ActionLog[index], Action: a, PlayerID: playerID}
+	b.queue = append(b.queue, queuedEvent{event: e, subs: subs})
+}
+
+// unlock releases the game mutex and delivers the queued events, unless
+// another call is delivering them already.
+func (g *Game) unlock() {
+	b := &g.events
+	b.mu.Lock()
+	if b.delivering || len(b.queue) == 0 {
+		b.mu.Unlock()
+		g.mu.Unlock()
+		return
+	}
+	b.delivering = true
+	b.mu.Unlock()
+	g.mu.Unlock()
+	done := false
+	defer func() {
+		if !done {
+			// a panicking subscriber leaves the rest of the queue to the
+			// next call
+			b.mu.Lock()
+			b.delivering = false
+			b.mu.Unlock()
+		}
+	}()
+	for {
+		b.mu.Lock()
+		if len(b.queue) == 0 {
+			b.delivering = false
+			done = true
+			b.mu.Unlock()
+			return
+		}
+		q := b.queue[0]
+		b.queue = b.queue[1:]
+		b.mu.Unlock()
+		for _, fn := range q.subs {
+			fn(q.event)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSubscribeEvents(t *testing.T) {
+	g := NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.MinBuyIn = 100
+	var events []Event
+	unsubscribe := g.Subscribe(func(e Event) {
+		// the game mutex is released while events are delivered
+		g.PotTotal()
+		events = append(events, e)
+	})
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 500); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := g.DealHoleCards(); err != nil {
+		t.Fatalf("deal: %v", err)
+	}
+	winner := g.ActionOn()
+	if err := g.AddAction(winner, ActionRaise, 300); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(g.ActionOn(), ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{winner: 200}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+
+	want := []EventType{EventBuyIn, EventBuyIn, EventGameStarted, EventStreetDealt, EventBlindPosted, EventBlindPosted,
+		EventActionTaken, EventActionTaken, EventActionTaken, EventPotAwarded, EventGameEnded}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events got %d: %+v", len(want), len(events), events)
+	}
+	for i, e := range events {
+		// the version header is not published
+		if e.Type != want[i] || e.Index != i+1 || e.Entry != g.ActionLog[i+1] {
+			t.Errorf("event %d got %s at %d want %s", i, e.Type, e.Index, want[i])
+		}
+	}
+	if events[0].PlayerID != players[0] || events[6].PlayerID != winner || events[6].Action.Code != ActionRaise {
+		t.Fatalf("unexpected player events %+v %+v", events[0], events[6])
+	}
+
+	unsubscribe()
+	if err := g.Join(uuid.New()); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected no events after unsubscribing got %+v", events[len(want):])
+	}
+}
+
+func TestSubscriberActsOnEvents(t *testing.T) {
+	g, _ := newStartedGame(t, nil, 500, 500, 500)
+	var mu sync.Mutex
+	var types []EventType
+	g.Subscribe(func(e Event) {
+		mu.Lock()
+		types = append(types, e.Type)
+		mu.Unlock()
+		if e.Type == EventActionTaken && e.Action.Code == ActionRaise {
+			// folding from the callback is logged and delivered after it
+			if err := g.AddAction(g.ActionOn(), ActionFold, 0); err != nil {
+				t.Errorf("fold: %v", err)
+			}
+		}
+	})
+	g.DealHands()
+	if err := g.AddAction(g.ActionOn(), ActionRaise, 300); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(types) != 2 || types[0] != EventActionTaken || types[1] != EventActionTaken {
+		t.Fatalf("expected the raise then the fold got %v", types)
+	}
+}
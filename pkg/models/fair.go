@@ -0,0 +1,231 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Errors returned by the provably fair shuffle.
+var (
+	ErrBadClientSeed   = errors.New("client seed must be 1 to 64 letters or digits")
+	ErrSeedsClosed     = errors.New("client seeds are only accepted before the shuffle")
+	ErrShufflerUsed    = errors.New("fair shuffler already used")
+	ErrNotFair         = errors.New("game not shuffled by a FairShuffler")
+	ErrSeedNotRevealed = errors.New("server seed not revealed")
+	ErrBadCommitment   = errors.New("server seed does not match the commitment")
+	ErrDeckMismatch    = errors.New("deck does not match the seeds")
+)
+
+// FairShuffler is a provably fair Shuffler using a commit-reveal scheme.
+// It draws a secret server seed when created and publishes its SHA-256 hash
+// as the commitment before any card is dealt. Players may add client seeds
+// until the game starts; the deck is then shuffled from the server seed
+// combined with the client seeds. The commitment and client seeds are
+// recorded in the start entry and the server seed is revealed in the end
+// entry, so anyone can check the deck with VerifyShuffle.
+//
+// The deck is a Fisher–Yates shuffle of the cards 1-52, or of the 36 cards
+// of a short deck in the same order, driven by the key HMAC-SHA256(server
+// seed, client seeds joined by ":"). Random numbers are read as big-endian
+// uint32 values from the blocks SHA-256(key || n) for n = 0, 1, ... (n as a
+// big-endian uint64). For i from the last index down to 1, with k = i+1,
+// values v at or above limit = (2^32 / k) * k are rejected and the next one
+// is drawn; the first v below limit gives j = v mod k and cards i and j are
+// swapped. Games dealt in DealPerHand mode shuffle a new deck
+// for every hand: the first with this key and hand n from the second on
+// with HMAC-SHA256(server seed, client seeds joined by ":" followed by "#"
+// and n in decimal).
+type FairShuffler struct {
+	serverSeed  []byte
+	clientSeeds []string
+	used        bool
+}
+
+// NewFairShuffler returns a FairShuffler with a fresh server seed drawn
+// from crypto/rand.
+func NewFairShuffler() (*FairShuffler, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return &FairShuffler{serverSeed: seed}, nil
+}
+
+// Commitment returns the hex encoded SHA-256 hash of the server seed. It is
+// announced to the players before they send their client seeds.
+func (f *FairShuffler) Commitment() string {
+	sum := sha256.Sum256(f.serverSeed)
+	return hex.EncodeToString(sum[:])
+}
+
+// AddClientSeed adds a player's seed to the shuffle. Seeds are 1 to 64
+// letters or digits and are only accepted before the deck is shuffled.
+func (f *FairShuffler) AddClientSeed(seed string) error {
+	if f.used {
+		return ErrSeedsClosed
+	}
+	if !validClientSeed(seed) {
+		return ErrBadClientSeed
+	}
+	f.clientSeeds = append(f.clientSeeds, seed)
+	return nil
+}
+
+// ClientSeeds returns the client seeds in the order they were added.
+func (f *FairShuffler) ClientSeeds() []string {
+	return append([]string{}, f.clientSeeds...)
+}
+
+// ServerSeed returns the hex encoded server seed. It must stay secret until
+// the game has ended.
+func (f *FairShuffler) ServerSeed() string {
+	return hex.EncodeToString(f.serverSeed)
+}
+
+// Shuffle shuffles cards in place from the combined seeds and closes the
+// shuffler to further client seeds. Each FairShuffler shuffles one deck
+// only, since its server seed is revealed when the game ends.
+func (f *FairShuffler) Shuffle(cards []int) error {
+	if f.used {
+		return ErrShufflerUsed
+	}
+	f.used = true
+	fairShuffle(cards, f.serverSeed, f.clientSeeds, 1)
+	return nil
+}
+
+// Name returns "fair".
+func (f *FairShuffler) Name() string {
+	return "fair"
+}
+
+// AddClientSeed adds a player's seed to the deck of a game shuffled by a
+// FairShuffler. The player must have bought in and the game must not have
+// started.
+func (g *Game) AddClientSeed(playerID uuid.UUID, seed string) error {
+	g.mu.Lock()
+	defer g.unlock()
+	f, ok := g.Shuffler.(*FairShuffler)
+	if !ok {
+		return ErrNotFair
+	}
+	if !g.StartedTime.IsZero() {
+		return ErrSeedsClosed
+	}
+	if _, ok := g.Stacks[playerID]; !ok {
+		return fmt.Errorf("player %s has not bought in", playerID)
+	}
+	return f.AddClientSeed(seed)
+}
+
+// VerifyShuffle checks the deck of a game shuffled by a FairShuffler from
+// its action log alone. The server seed revealed in the end entry must hash
+// to the commitment in the start entry, and the deck derived from the seeds
+// must deal every card recorded in the log. The derived deck is returned;
+// for games dealing a new deck every hand it holds the decks of all hands
+// in order.
+func VerifyShuffle(log ActionLog) (IntSlice, error) {
+	var start *GameStart
+	var seed string
+	for _, entry := range log {
+		a, err := ParseAction(entry)
+		if err != nil {
+			return nil, err
+		}
+		switch a.Kind {
+		case EntryStart:
+			start = a.Start
+		case EntryEnd:
+			seed = a.Seed
+		}
+	}
+	if start == nil || start.Shuffler != (&FairShuffler{}).Name() || start.Commitment == "" {
+		return nil, ErrNotFair
+	}
+	if seed == "" {
+		return nil, ErrSeedNotRevealed
+	}
+	serverSeed, err := hex.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadCommitment, err)
+	}
+	if (&FairShuffler{serverSeed: serverSeed}).Commitment() != start.Commitment {
+		return nil, ErrBadCommitment
+	}
+	hands := 1
+	if start.Deal == DealPerHand {
+		hands = log.Hands()
+	}
+	deck := IntSlice{}
+	for hand := 1; hand <= hands; hand++ {
+		cards := append(IntSlice{}, start.Variant.Deck()...)
+		fairShuffle(cards, serverSeed, start.ClientSeeds, hand)
+		deck = append(deck, cards...)
+	}
+	if _, err := Replay(log, deck); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeckMismatch, err)
+	}
+	return deck, nil
+}
+
+// fairShuffle runs the Fisher–Yates shuffle described on FairShuffler for
+// the deck of the given hand, counted from 1.
+func fairShuffle(cards []int, serverSeed []byte, clientSeeds []string, hand int) {
+	message := strings.Join(clientSeeds, ":")
+	if hand > 1 {
+		// client seeds are letters and digits, so the hand number cannot
+		// be mistaken for a seed
+		message += "#" + strconv.Itoa(hand)
+	}
+	mac := hmac.New(sha256.New, serverSeed)
+	mac.Write([]byte(message))
+	key := mac.Sum(nil)
+
+	var block []byte
+	var counter uint64
+	next := func() uint32 {
+		if len(block) == 0 {
+			buf := make([]byte, len(key)+8)
+			copy(buf, key)
+			binary.BigEndian.PutUint64(buf[len(key):], counter)
+			sum := sha256.Sum256(buf)
+			block = sum[:]
+			counter++
+		}
+		v := binary.BigEndian.Uint32(block)
+		block = block[4:]
+		return v
+	}
+	for i := len(cards) - 1; i > 0; i-- {
+		k := uint64(i + 1)
+		limit := (1 << 32) / k * k
+		v := uint64(next())
+		for v >= limit {
+			v = uint64(next())
+		}
+		j := int(v % k)
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+}
+
+// validClientSeed reports whether seed can be recorded in the start entry.
+func validClientSeed(seed string) bool {
+	if len(seed) == 0 || len(seed) > 64 {
+		return false
+	}
+	for _, c := range seed {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
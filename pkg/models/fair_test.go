@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// playFairGame deals a hand up to the flop with a fair shuffle seeded by
+// both players and ends the game.
+func playFairGame(t *testing.T) (*Game, *FairShuffler) {
+	t.Helper()
+	f, err := NewFairShuffler()
+	if err != nil {
+		t.Fatalf("shuffler: %v", err)
+	}
+	g := NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.Shuffler = f
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	for i, p := range players {
+		if err := g.BuyIn(p, 1000); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+		if err := g.AddClientSeed(p, []string{"lucky7", "Q2xpZW50"}[i]); err != nil {
+			t.Fatalf("client seed: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	checkOrCall(t, g)
+	checkOrCall(t, g)
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	return g, f
+}
+
+func TestVerifyShuffle(t *testing.T) {
+	g, f := playFairGame(t)
+	start := startEntry(t, g)
+	if start.Shuffler != "fair" || start.Commitment != f.Commitment() || !reflect.DeepEqual(start.ClientSeeds, []string{"lucky7", "Q2xpZW50"}) {
+		t.Fatalf("unexpected start entry %+v", start)
+	}
+	end, err := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if err != nil || end.Seed != f.ServerSeed() {
+		t.Fatalf("expected the server seed revealed got %s", g.ActionLog[len(g.ActionLog)-1])
+	}
+	deck, err := VerifyShuffle(g.ActionLog)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !reflect.DeepEqual(deck, g.CardSequence) {
+		t.Fatalf("expected the dealt deck got %v", deck)
+	}
+	replayed, err := Replay(g.ActionLog, g.CardSequence)
+	if err != nil || !replayed.Ended() {
+		t.Fatalf("replay: %v", err)
+	}
+}
+
+func TestVerifyShuffleRejects(t *testing.T) {
+	g, f := playFairGame(t)
+	last := len(g.ActionLog) - 1
+	if _, err := VerifyShuffle(g.ActionLog[:last]); !errors.Is(err, ErrSeedNotRevealed) {
+		t.Fatalf("expected ErrSeedNotRevealed got %v", err)
+	}
+	other, _ := NewFairShuffler()
+	forged := append(ActionLog{}, g.ActionLog...)
+	forged[last] = strings.Replace(forged[last], f.ServerSeed(), other.ServerSeed(), 1)
+	if _, err := VerifyShuffle(forged); !errors.Is(err, ErrBadCommitment) {
+		t.Fatalf("expected ErrBadCommitment got %v", err)
+	}
+	// a deck dealt from other seeds does not fit the board in the log
+	forged = append(ActionLog{}, g.ActionLog...)
+	for i, entry := range forged {
+		forged[i] = strings.Replace(entry, "clients=lucky7.", "clients=lucky8.", 1)
+	}
+	if _, err := VerifyShuffle(forged); !errors.Is(err, ErrDeckMismatch) {
+		t.Fatalf("expected ErrDeckMismatch got %v", err)
+	}
+	plain, _ := newStartedGame(t, nil, 1000, 1000)
+	if _, err := VerifyShuffle(plain.ActionLog); !errors.Is(err, ErrNotFair) {
+		t.Fatalf("expected ErrNotFair got %v", err)
+	}
+}
+
+func TestAddClientSeed(t *testing.T) {
+	f, err := NewFairShuffler()
+	if err != nil {
+		t.Fatalf("shuffler: %v", err)
+	}
+	g := NewGame(uuid.New(), 2)
+	p := uuid.New()
+	if err := g.AddClientSeed(p, "abc"); !errors.Is(err, ErrNotFair) {
+		t.Fatalf("expected ErrNotFair got %v", err)
+	}
+	g.Shuffler = f
+	if err := g.AddClientSeed(p, "abc"); err == nil {
+		t.Fatal("expected error for a player without a buy-in")
+	}
+	if err := g.BuyIn(p, 1000); err != nil {
+		t.Fatalf("buyin: %v", err)
+	}
+	for _, seed := range []string{"", "not:allowed", strings.Repeat("a", 65)} {
+		if err := g.AddClientSeed(p, seed); !errors.Is(err, ErrBadClientSeed) {
+			t.Errorf("%q: expected ErrBadClientSeed got %v", seed, err)
+		}
+	}
+	if err := f.Shuffle(make([]int, 52)); err != nil {
+		t.Fatalf("shuffle: %v", err)
+	}
+	if err := f.AddClientSeed("late"); !errors.Is(err, ErrSeedsClosed) {
+		t.Fatalf("expected ErrSeedsClosed got %v", err)
+	}
+	if err := f.Shuffle(make([]int, 52)); !errors.Is(err, ErrShufflerUsed) {
+		t.Fatalf("expected ErrShufflerUsed got %v", err)
+	}
+}
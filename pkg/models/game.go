@@ -7,10 +7,9 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
-	"math/rand"
 	"pokerDB/pkg/constants"
-	"strconv"
-	"strings"
+	"pokerDB/pkg/rules/betting"
+	"pokerDB/pkg/rules/rake"
 	"sync"
 	"time"
 )
@@ -22,7 +21,7 @@ const MaxSeats = 9
 type Game struct {
 	ID              uuid.UUID           `json:"id" gorm:"primary_key;type:uuid"`
 	TableID         uuid.UUID           `json:"table_id" gorm:"type:uuid"`
-	CardSequence    IntSlice            `json:"card_sequence" gorm:"type:json"`
+	CardSequence    IntSlice            `json:"-" gorm:"type:json"` // secret, see RevealDeck
 	StartedTime     time.Time           `json:"started_time" gorm:"type:timestamp"`
 	EndedTime       time.Time           `json:"ended_time" gorm:"type:timestamp"`
 	PersonCount     int                 `json:"person_count" gorm:"type:integer"`
@@ -31,18 +30,52 @@ type Game struct {
 	BigBlind        int64               `json:"big_blind" gorm:"type:bigint"`
 	AllowRunItTwice bool                `json:"allow_run_it_twice" gorm:"type:boolean"`
 	AllowStraddle   bool                `json:"allow_straddle" gorm:"type:boolean"`
+	Betting         betting.Limits      `json:"betting" gorm:"serializer:json"`
+	Variant         Variant             `json:"variant" gorm:"type:text"`
+	Rake            rake.Rules          `json:"rake" gorm:"serializer:json"`
+	DecisionTime    time.Duration       `json:"decision_time" gorm:"type:bigint"` // time per decision, 0 for no action clock
+	TimeBank        time.Duration       `json:"time_bank" gorm:"type:bigint"`     // extra time each player may draw on per game
 	MinBuyIn        int64               `json:"min_buy_in" gorm:"type:bigint"`
 	MaxBuyIn        int64               `json:"max_buy_in" gorm:"type:bigint"`
+	MaxRebuys       int                 `json:"max_rebuys" gorm:"type:integer"` // rebuys per player, 0 for no limit
 	BuyIns          BuyInList           `json:"buy_ins" gorm:"type:json"`
 	ActionLog       ActionLog           `json:"action_log" gorm:"type:json"`
 	Ledgers         []Ledger            `json:"ledgers"`
-	NextCardIndex   int                 `json:"-" gorm:"-"`
+	Shuffler        Shuffler            `json:"-" gorm:"-"` // shuffles the deck at Start, CryptoShuffler if nil
+	Clock           Clock               `json:"-" gorm:"-"` // tells the time, SystemClock if nil
 	CurrentRound    int                 `json:"current_round" gorm:"-"`
-	CurrentDealer   int                 `json:"current_dealer" gorm:"-"`
+	CurrentDealer   int                 `json:"current_dealer" gorm:"-"` // seat of the dealer button
+	SmallBlindSeat  int                 `json:"small_blind_seat" gorm:"-"`
+	BigBlindSeat    int                 `json:"big_blind_seat" gorm:"-"`
+	CurrentStreet   Street              `json:"current_street" gorm:"-"`
+	Board           IntSlice            `json:"board" gorm:"-"`
+	Boards          []IntSlice          `json:"boards,omitempty" gorm:"-"`
 	Stacks          map[uuid.UUID]int64 `json:"-" gorm:"-"`
 	Seats           map[uuid.UUID]int   `json:"-" gorm:"-"`
 	NextSeats       map[uuid.UUID]int   `json:"-" gorm:"-"`
 	currentBets     map[uuid.UUID]int64 `json:"-" gorm:"-"`
+	betRound        betting.Round       `json:"-" gorm:"-"`
+	contributed     map[uuid.UUID]int64 `json:"-" gorm:"-"`
+	inHand          []uuid.UUID         `json:"-" gorm:"-"`
+	holeCards       map[uuid.UUID][]int `json:"-" gorm:"-"`
+	folded          map[uuid.UUID]bool  `json:"-" gorm:"-"`
+	acted           map[uuid.UUID]bool  `json:"-" gorm:"-"`
+	actionOn        int                 `json:"-" gorm:"-"`
+	lastBlind       int                 `json:"-" gorm:"-"`
+	button          int                 `json:"-" gorm:"-"`
+	logVersion      int                 `json:"-" gorm:"-"`
+	fair            *FairShuffler       `json:"-" gorm:"-"`
+	raked           int64               `json:"-" gorm:"-"`
+	cashedOut       map[uuid.UUID]int64 `json:"-" gorm:"-"`
+	clock           turnClock           `json:"-" gorm:"-"`
+	events          eventBus            `json:"-" gorm:"-"`
+	sitting         sitStatuses         `json:"-" gorm:"-"`
+	deck            *Deck               `json:"-" gorm:"-"`
+	dealMode        string              `json:"-" gorm:"-"`
+	straddlers      map[uuid.UUID]bool  `json:"-" gorm:"-"`
+	runs            map[uuid.UUID]int   `json:"-" gorm:"-"`
+	holeDealt       bool                `json:"-" gorm:"-"`
+	bettingClosed   bool                `json:"-" gorm:"-"`
 	inRound         bool                `json:"-" gorm:"-"`
 	mu              sync.RWMutex        `json:"-" gorm:"-"`
 }
@@ -76,7 +109,7 @@ func (g *Game) Ended() bool {
 
 func (g *Game) Start() error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlock()
 	// check timestamps directly while holding the lock to avoid
 	// re-entrantly acquiring the mutex via Started() or Ended()
 	if !g.StartedTime.IsZero() {
@@ -99,40 +132,113 @@ func (g *Game) Start() error {
 		return errors.New("too many players")
 	}
 
-	if len(g.BuyIns) != g.PersonCount {
+	if g.BuyIns.Players() != g.PersonCount {
 		logrus.Warn("buy-ins not complete")
 		return errors.New("all players must buy in before start")
 	}
 
 	for _, b := range g.BuyIns {
+		// top-ups were checked against the maximum when they were made
+		if b.Carried || b.TopUp || b.Rebuy {
+			continue
+		}
 		if b.Amount < g.MinBuyIn || (g.MaxBuyIn > 0 && b.Amount > g.MaxBuyIn) {
 			return fmt.Errorf("invalid buy-in for player %s", b.PlayerID)
 		}
 	}
 
-	g.StartedTime = time.Now()
+	if err := g.Rake.Validate(); err != nil {
+		logrus.Warn("Invalid rake")
+		return err
+	}
+	if err := g.Betting.Validate(); err != nil {
+		logrus.Warn("Invalid betting limits")
+		return err
+	}
+	if err := g.Variant.Validate(); err != nil {
+		logrus.Warn("Invalid variant")
+		return err
+	}
+	// every hand needs the hole cards, five board cards and three burns
+	deck := g.Variant.Deck()
+	if g.PersonCount*g.Variant.HoleCards()+8 > len(deck) {
+		logrus.Warn("Too many players for the variant")
+		return fmt.Errorf("too many players for %s", g.Variant)
+	}
+	if g.DecisionTime < 0 || g.TimeBank < 0 {
+		logrus.Warn("Invalid action clock")
+		return errors.New("decision time and time bank must not be negative")
+	}
+
+	shuffler := g.Shuffler
+	if shuffler == nil {
+		shuffler = CryptoShuffler{}
+	}
+	shuffled := make([]int, len(deck))
+	copy(shuffled, deck)
+	if err := shuffler.Shuffle(shuffled); err != nil {
+		logrus.Warn("Shuffle failed")
+		return fmt.Errorf("shuffle: %w", err)
+	}
+	start := &GameStart{Shuffler: shuffler.Name(), Deal: DealPerHand, Rake: g.Rake}
+	if f, ok := shuffler.(*FairShuffler); ok {
+		start.Commitment = f.Commitment()
+		start.ClientSeeds = f.ClientSeeds()
+		g.fair = f
+	}
+	g.startNoLock(IntSlice(shuffled), start, g.nowNoLock())
+	return nil
+}
+
+// startNoLock starts the game with the given deck, logs the start entry
+// with the game options and the shuffle fields of start and opens the first
+// hand. The caller must hold the mutex and have validated the game.
+func (g *Game) startNoLock(cards IntSlice, start *GameStart, at time.Time) {
+	g.StartedTime = at
 	g.CurrentRound = 1
-	g.CurrentDealer = 0
 	g.inRound = true
 	g.currentBets = make(map[uuid.UUID]int64)
 	g.Stacks = make(map[uuid.UUID]int64)
 	for _, b := range g.BuyIns {
-		g.Stacks[b.PlayerID] = b.Amount
-	}
-	shuffled := make([]int, len(constants.CardSequence))
-	copy(shuffled, constants.CardSequence)
-	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
-	logrus.Info("Shuffled Sequences: ", shuffled)
-	g.CardSequence = IntSlice(shuffled)
-	g.NextCardIndex = 0
-	startEntry := fmt.Sprintf("G:%d:%d:%d:%d:%d,%d", g.SmallBlind, g.BigBlind, g.Ante, boolToInt(g.AllowRunItTwice), boolToInt(g.AllowStraddle), g.StartedTime.Unix())
-	g.ActionLog = append(g.ActionLog, startEntry)
-	return nil
+		g.Stacks[b.PlayerID] += b.Amount
+	}
+	g.CardSequence = cards
+	g.deck = NewDeck(cards)
+	g.dealMode = start.Deal
+	g.raked = 0
+	g.clock = turnClock{}
+	g.logNoLock(Action{
+		Kind: EntryStart,
+		Start: &GameStart{
+			SmallBlind: g.SmallBlind,
+			BigBlind:   g.BigBlind,
+			Ante:       g.Ante,
+			RunItTwice: g.AllowRunItTwice,
+			Straddle:   g.AllowStraddle,
+			Betting:    g.Betting,
+			Variant:    g.Variant,
+			// a game continuing a table records where the button was
+			ButtonSeat:     g.CurrentDealer,
+			SmallBlindSeat: g.SmallBlindSeat,
+			BigBlindSeat:   g.BigBlindSeat,
+			Shuffler:       start.Shuffler,
+			Commitment:     start.Commitment,
+			ClientSeeds:    start.ClientSeeds,
+			Deal:           start.Deal,
+			Rake:           g.Rake,
+			DecisionTime:   g.DecisionTime,
+			TimeBank:       g.TimeBank,
+		},
+		Time: g.StartedTime,
+	})
+	g.startHandNoLock()
 }
 
+// End settles the game and logs the end entry with its ledger, see Settle.
+// The game does not end if the ledger does not balance.
 func (g *Game) End() error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlock()
 	// check timestamps directly to avoid re-entrant mutex locking
 	if g.StartedTime.IsZero() {
 		logrus.Warn("Game not started")
@@ -142,123 +248,291 @@ func (g *Game) End() error {
 		logrus.Warn("Game already ended")
 		return errors.New("game already ended")
 	}
-	g.EndedTime = time.Now()
-	g.inRound = false
-	pairs := make([]string, len(g.Ledgers))
-	for i, l := range g.Ledgers {
-		id := l.PlayerID.String()
-		if len(id) > 8 {
-			id = id[:8]
-		}
-		pairs[i] = fmt.Sprintf("%s=%d", id, l.Balance)
-	}
-	endEntry := fmt.Sprintf("E:%s,%d", strings.Join(pairs, ":"), g.EndedTime.Unix())
-	g.ActionLog = append(g.ActionLog, endEntry)
-	return nil
+	return g.endNoLock()
 }
 
-// endNoLock finalizes the game without locking. The caller must hold the mutex.
-func (g *Game) endNoLock() {
+// endNoLock settles and finalizes the game without locking. The caller must
+// hold the mutex.
+func (g *Game) endNoLock() error {
 	if !g.EndedTime.IsZero() {
-		return
+		return nil
+	}
+	ledgers, err := g.settleNoLock()
+	if err != nil {
+		logrus.Warn("Ledger does not balance")
+		return err
 	}
-	g.EndedTime = time.Now()
+	g.refundHandNoLock()
+	g.Ledgers = ledgers
+	g.finishNoLock()
+	return nil
+}
+
+// finishNoLock marks the game ended and logs the end entry with the ledger
+// as it is. The caller must hold the mutex.
+func (g *Game) finishNoLock() {
+	g.EndedTime = g.nowNoLock()
 	g.inRound = false
-	pairs := make([]string, len(g.Ledgers))
+	g.clearHandNoLock()
+	g.logEndNoLock()
+}
+
+// logEndNoLock logs the end entry with the ledger balances and, for a fair
+// shuffle, the server seed. The caller must hold the mutex.
+func (g *Game) logEndNoLock() {
+	balances := make([]LedgerBalance, len(g.Ledgers))
 	for i, l := range g.Ledgers {
-		id := shortID(l.PlayerID)
-		pairs[i] = fmt.Sprintf("%s=%d", id, l.Balance)
+		balances[i] = LedgerBalance{Player: g.logIDNoLock(l.PlayerID), Balance: l.Balance}
+		if l.PlayerID == HouseID {
+			balances[i].Player = HousePlayer
+		}
 	}
-	endEntry := fmt.Sprintf("E:%s,%d", strings.Join(pairs, ":"), g.EndedTime.Unix())
-	g.ActionLog = append(g.ActionLog, endEntry)
+	end := Action{Kind: EntryEnd, Balances: balances, Time: g.EndedTime}
+	if g.fair != nil {
+		// the game is over, so the server seed can be revealed
+		end.Seed = g.fair.ServerSeed()
+	}
+	g.logNoLock(end)
 }
 
-// EndRound finishes the current round and rotates the dealer position.
+// EndRound finishes the current round. The button moves to its next seat
+// when the following round starts.
 func (g *Game) EndRound() error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlock()
 	if !g.inRound {
 		return errors.New("round not active")
 	}
 	g.inRound = false
-	g.currentBets = make(map[uuid.UUID]int64)
-	g.CurrentDealer = (g.CurrentDealer + 1) % g.PersonCount
+	g.clearHandNoLock()
 	return nil
 }
 
-// StartRound begins a new round after the previous one has ended.
+// StartRound begins a new round after the previous one has ended. Games
+// dealt in DealPerHand mode shuffle a new deck for it, and the deck is
+// added to the card sequence so the hand can be replayed. Other games deal
+// on from the same deck and fail with ErrDeckExhausted before any blind is
+// posted when it cannot cover the hand.
 func (g *Game) StartRound() error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlock()
 	if g.StartedTime.IsZero() {
 		return errors.New("game not started")
 	}
 	if g.inRound {
 		return errors.New("round already active")
 	}
+	players := g.dealtInNoLock()
+	if len(players) < 2 {
+		return errors.New("not enough players with chips")
+	}
+	if g.dealMode == DealPerHand {
+		cards, err := g.shuffleDeckNoLock(g.CurrentRound + 1)
+		if err != nil {
+			logrus.Warn("Shuffle failed")
+			return fmt.Errorf("shuffle: %w", err)
+		}
+		g.CardSequence = append(g.CardSequence, cards...)
+		g.deck.Refill(cards)
+	} else if need := g.handNeedNoLock(len(players)); g.deck.Remaining() < need {
+		// games dealing every hand from one deck stop before the blinds
+		// are posted for a hand that cannot be dealt
+		return fmt.Errorf("%w: %d cards needed, %d left", ErrDeckExhausted, need, g.deck.Remaining())
+	}
 	g.CurrentRound++
 	g.inRound = true
-	g.currentBets = make(map[uuid.UUID]int64)
+	g.startHandNoLock()
 	return nil
 }
 
-// Deal removes the first count cards from the game's card sequence and returns
-// them. If there are not enough cards remaining, an empty slice is returned.
-// Deal returns the next 'count' cards from the sequence without modifying the
-// underlying deck. The index is advanced so subsequent calls return the next
-// cards in order. If there are not enough cards remaining, an empty slice is
-// returned.
-func (g *Game) dealNoLock(count int) []int {
-	if g.NextCardIndex+count > len(g.CardSequence) {
+// shuffleDeckNoLock returns a deck of the game's variant shuffled for the
+// given hand. Hands after the first of a fair shuffle are shuffled from the
+// seeds and the hand number, see FairShuffler. The caller must hold the
+// mutex.
+func (g *Game) shuffleDeckNoLock(hand int) (IntSlice, error) {
+	cards := append(IntSlice{}, g.Variant.Deck()...)
+	if g.fair != nil {
+		fairShuffle(cards, g.fair.serverSeed, g.fair.clientSeeds, hand)
+		return cards, nil
+	}
+	shuffler := g.Shuffler
+	if shuffler == nil {
+		shuffler = CryptoShuffler{}
+	}
+	if err := shuffler.Shuffle(cards); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// handNeedNoLock returns the number of cards, burn cards included, needed
+// to deal a hand with a full board to players. The caller must hold the
+// mutex.
+func (g *Game) handNeedNoLock(players int) int {
+	need := players*g.Variant.HoleCards() + 5
+	if g.dealMode != DealLegacy {
+		need += 3
+	}
+	return need
+}
+
+// dealBoardNoLock burns a card and deals count community cards for a
+// street. Games dealt in DealLegacy mode do not burn. Nothing is dealt when
+// the deck cannot cover the burn and the cards. The caller must hold the
+// mutex.
+func (g *Game) dealBoardNoLock(count int) ([]int, error) {
+	if g.deck == nil {
+		return nil, errors.New("game not started")
+	}
+	if g.dealMode == DealLegacy {
+		return g.deck.Draw(count)
+	}
+	if g.deck.Remaining() < count+1 {
+		return nil, fmt.Errorf("%w: %d cards needed, %d left", ErrDeckExhausted, count+1, g.deck.Remaining())
+	}
+	g.deck.Burn()
+	return g.deck.Draw(count)
+}
+
+// Deal deals the community cards of the next street. The count must match
+// the number of cards that street requires (3 for the flop, 1 for the turn
+// and river) and betting on the current street must be complete. Calls that
+// do not fit the current street return an empty slice.
+func (g *Game) Deal(count int) []int {
+	g.mu.Lock()
+	defer g.unlock()
+	next, ok := nextStreets[g.CurrentStreet]
+	if !ok || boardCards[next] != count {
+		logrus.Warn("cannot deal ", count, " cards on ", g.CurrentStreet)
 		return []int{}
 	}
-	seq := g.CardSequence[g.NextCardIndex : g.NextCardIndex+count]
-	cards := make([]int, len(seq))
-	for i, v := range seq {
-		cards[i] = v
+	cards, err := g.nextStreetNoLock()
+	if err != nil {
+		logrus.Warn(err)
+		return []int{}
 	}
-	g.NextCardIndex += count
 	return cards
 }
 
-func (g *Game) Deal(count int) []int {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	return g.dealNoLock(count)
+// DealHands deals the hole cards of the game's variant, two cards in
+// hold'em and four to six in Omaha, to each player in the current hand and
+// returns a slice of hands in seat order. Hole cards can only be dealt once per hand,
+// at the start of the preflop street; otherwise, or when the deck is
+// exhausted, a warning is logged and an empty slice is returned. Use
+// DealHoleCards to receive the error.
+func (g *Game) DealHands() [][]int {
+	hands, err := g.DealHoleCards()
+	if err != nil {
+		logrus.Warn(err)
+		return [][]int{}
+	}
+	return hands
 }
 
-// DealHands deals two cards to each player and returns a slice of hands where
-// each hand contains two cards for one player.
-// DealHands returns a slice of player hands without altering the card
-// sequence, relying on Deal to advance the index.
-func (g *Game) DealHands() [][]int {
+// DealHoleCards deals the hole cards of the game's variant to each player
+// in the current hand, one card at a time starting left of the button, and returns the hands in seat
+// order. It fails with ErrDeckExhausted when the deck cannot cover every
+// hand, and when the hole cards cannot be dealt on the current street.
+func (g *Game) DealHoleCards() ([][]int, error) {
 	g.mu.Lock()
-	defer g.mu.Unlock()
-	hands := make([][]int, g.PersonCount)
-	for i := 0; i < g.PersonCount; i++ {
-		hands[i] = g.dealNoLock(2)
+	defer g.unlock()
+	return g.dealHoleCardsNoLock()
+}
+
+// dealHoleCardsNoLock deals the hole cards. The caller must hold the mutex.
+func (g *Game) dealHoleCardsNoLock() ([][]int, error) {
+	if !g.inRound || g.CurrentStreet != StreetPreflop || g.holeDealt {
+		return nil, fmt.Errorf("hole cards cannot be dealt on %s", g.CurrentStreet)
+	}
+	n := len(g.inHand)
+	count := g.Variant.HoleCards()
+	hands := make([][]int, n)
+	if g.dealMode == DealLegacy {
+		if g.deck.Remaining() < count*n {
+			return nil, fmt.Errorf("%w: %d cards needed, %d left", ErrDeckExhausted, count*n, g.deck.Remaining())
+		}
+		for i, pid := range g.inHand {
+			hands[i], _ = g.deck.DealTo(pid, count)
+		}
+	} else {
+		order := make([]uuid.UUID, n)
+		for k := range order {
+			order[k] = g.inHand[(g.button+1+k)%n]
+		}
+		dealt, err := g.deck.DealRound(order, count)
+		if err != nil {
+			return nil, err
+		}
+		for k, hand := range dealt {
+			hands[(g.button+1+k)%n] = hand
+		}
 	}
-	return hands
+	for i, pid := range g.inHand {
+		g.holeCards[pid] = hands[i]
+	}
+	g.holeDealt = true
+	// the first player's clock starts once the cards are out
+	g.startTurnNoLock()
+	return hands, nil
 }
 
-// BuyIn records a player's initial chip stack before the game starts.
+// BuyIn adds chips to a player's stack. A player's first buy-in in the game
+// is logged with the B code and may be made at any time. Later buy-ins are
+// rebuys (Y) when the player has no chips at the table and top-ups (V)
+// otherwise. They are not accepted from players dealt into a hand whose pot
+// has not been awarded yet, and at most MaxRebuys rebuys are allowed unless
+// it is 0. Initial buy-ins and rebuys must be between MinBuyIn and
+// MaxBuyIn, and no buy-in may take the stack above MaxBuyIn.
 func (g *Game) BuyIn(playerID uuid.UUID, amount int64) error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlock()
 	if !g.EndedTime.IsZero() {
 		return errors.New("game already ended")
 	}
-	if amount < g.MinBuyIn || (g.MaxBuyIn > 0 && amount > g.MaxBuyIn) {
+	b := BuyIn{PlayerID: playerID, Amount: amount, Fee: g.Rake.Fee}
+	code := ActionBuyIn
+	rebuys := 0
+	for _, prev := range g.BuyIns {
+		if prev.PlayerID != playerID {
+			continue
+		}
+		code = ActionTopUp
+		if prev.Rebuy {
+			rebuys++
+		}
+	}
+	stack := g.Stacks[playerID]
+	if code == ActionTopUp && stack == 0 {
+		code = ActionRebuy
+	}
+	switch code {
+	case ActionTopUp:
+		if amount <= 0 {
+			return errors.New("top-up must be positive")
+		}
+		b.TopUp = true
+	case ActionRebuy:
+		if g.MaxRebuys > 0 && rebuys >= g.MaxRebuys {
+			return fmt.Errorf("player %s used all %d rebuys", playerID, g.MaxRebuys)
+		}
+		b.Rebuy = true
+	}
+	if code != ActionTopUp && amount < g.MinBuyIn {
 		return fmt.Errorf("buy-in must be between %d and %d", g.MinBuyIn, g.MaxBuyIn)
 	}
-	id := playerID.String()
-	if len(id) > 8 {
-		id = id[:8]
+	if g.MaxBuyIn > 0 && stack+amount > g.MaxBuyIn {
+		return fmt.Errorf("stack of %d after buy-in exceeds %d", stack+amount, g.MaxBuyIn)
+	}
+	if code != ActionBuyIn && g.inRound && g.isInHandNoLock(playerID) && len(g.contributed) > 0 {
+		// the hand is over once its pot has been awarded
+		return fmt.Errorf("player %s may only %s between hands", playerID, ActionToWord(code))
 	}
-	g.BuyIns = append(g.BuyIns, BuyIn{PlayerID: playerID, Amount: amount})
+	g.BuyIns = append(g.BuyIns, b)
 	g.Stacks[playerID] += amount
-	entry := fmt.Sprintf("%s%s%d,%d", id, ActionBuyIn, amount, time.Now().Unix())
-	g.ActionLog = append(g.ActionLog, entry)
+	g.logActionNoLock(playerID, code, amount)
+	if g.Rake.Fee > 0 {
+		g.logActionNoLock(playerID, ActionFee, g.Rake.Fee)
+	}
 	return nil
 }
 
@@ -267,14 +541,13 @@ func (g *Game) BuyIn(playerID uuid.UUID, amount int64) error {
 func (g *Game) Join(playerID uuid.UUID) error {
 	g.mu.Lock()
 	if _, ok := g.Seats[playerID]; ok {
-		g.mu.Unlock()
+		g.unlock()
 		return fmt.Errorf("player %s already joined", playerID)
 	}
 	g.Seats[playerID] = -1
 	g.PersonCount = len(g.Seats)
-	entry := fmt.Sprintf("%s%s0,%d", shortID(playerID), ActionJoin, time.Now().Unix())
-	g.ActionLog = append(g.ActionLog, entry)
-	g.mu.Unlock()
+	g.logActionNoLock(playerID, ActionJoin, 0)
+	g.unlock()
 	return nil
 }
 
@@ -283,26 +556,33 @@ func (g *Game) Join(playerID uuid.UUID) error {
 func (g *Game) Quit(playerID uuid.UUID) error {
 	g.mu.Lock()
 	if _, ok := g.Seats[playerID]; !ok {
-		g.mu.Unlock()
+		g.unlock()
 		return fmt.Errorf("unknown player %s", playerID)
 	}
 	delete(g.Seats, playerID)
+	delete(g.sitting, playerID)
+	// chips still in the pot stay there and are cashed out only if the
+	// hand is not awarded
+	g.cashOutNoLock(playerID, g.Stacks[playerID])
 	delete(g.Stacks, playerID)
-	delete(g.currentBets, playerID)
+	if g.inRound && g.isInHandNoLock(playerID) && !g.folded[playerID] {
+		g.folded[playerID] = true
+		g.updateBettingNoLock()
+		if g.bettingClosed || g.actionOnNoLock() == playerID {
+			g.advanceActionNoLock(g.actionOn)
+		}
+	}
 	g.PersonCount = len(g.Seats)
-	entry := fmt.Sprintf("%s%s0,%d", shortID(playerID), ActionQuit, time.Now().Unix())
-	g.ActionLog = append(g.ActionLog, entry)
+	g.logActionNoLock(playerID, ActionQuit, 0)
 	shouldEnd := g.PersonCount == 0 && g.EndedTime.IsZero()
-	g.mu.Unlock()
+	g.unlock()
 	if shouldEnd {
 		if g.Started() {
 			return g.End()
 		}
 		g.mu.Lock()
-		if g.EndedTime.IsZero() {
-			g.endNoLock()
-		}
-		g.mu.Unlock()
+		defer g.unlock()
+		return g.endNoLock()
 	}
 	return nil
 }
@@ -312,7 +592,7 @@ func (g *Game) Quit(playerID uuid.UUID) error {
 // purposes.
 func (g *Game) ChooseSeat(playerID uuid.UUID, seat int) error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlock()
 	if _, ok := g.Seats[playerID]; !ok {
 		return fmt.Errorf("unknown player %s", playerID)
 	}
@@ -325,39 +605,74 @@ func (g *Game) ChooseSeat(playerID uuid.UUID, seat int) error {
 		}
 	}
 	g.NextSeats[playerID] = seat
-	entry := fmt.Sprintf("%s%s%d,%d", shortID(playerID), ActionSeat, seat, time.Now().Unix())
-	g.ActionLog = append(g.ActionLog, entry)
+	g.logActionNoLock(playerID, ActionSeat, int64(seat))
 	return nil
 }
 
-// AddAction appends a new action to the game.
+// AddAction appends a new action to the game. Actions are only accepted from
+// the player the action is on while betting on the current street is open,
+// and must be one of the player's legal actions. Acting out of turn returns
+// a *TurnError.
 func (g *Game) AddAction(playerID uuid.UUID, code string, amount int64) error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	defer g.unlock()
+	return g.addActionNoLock(playerID, code, amount)
+}
 
+func (g *Game) addActionNoLock(playerID uuid.UUID, code string, amount int64) error {
 	if !g.inRound {
 		return errors.New("no active round")
 	}
+	if !g.holeDealt {
+		return errors.New("hole cards not dealt")
+	}
+	if g.bettingClosed {
+		return fmt.Errorf("betting closed on %s", g.CurrentStreet)
+	}
 	stack, ok := g.Stacks[playerID]
 	if !ok {
 		return fmt.Errorf("unknown player %s", playerID)
 	}
-	need := amount - g.currentBets[playerID]
-	if need < 0 {
-		need = 0
+	if !g.isInHandNoLock(playerID) {
+		return fmt.Errorf("player %s not in hand", playerID)
+	}
+	if g.folded[playerID] {
+		return fmt.Errorf("player %s has folded", playerID)
 	}
-	if need > stack {
-		return fmt.Errorf("insufficient chips")
+	if stack == 0 {
+		return fmt.Errorf("player %s is all-in", playerID)
+	}
+	if err := g.checkLegalNoLock(playerID, code, amount); err != nil {
+		return err
 	}
-	g.Stacks[playerID] = stack - need
-	g.currentBets[playerID] = amount
 
-	id := playerID.String()
-	if len(id) > 8 {
-		id = id[:8]
+	if code == ActionFold {
+		g.folded[playerID] = true
+	} else {
+		need := amount - g.currentBets[playerID]
+		if need < 0 {
+			need = 0
+		}
+		if need > stack {
+			return fmt.Errorf("insufficient chips")
+		}
+		g.Stacks[playerID] = stack - need
+		g.currentBets[playerID] += need
+		g.contributed[playerID] += need
+		if g.betRound.Apply(g.currentBets[playerID]) {
+			// a full raise reopens the action for everybody facing it
+			for pid := range g.acted {
+				if g.betRound.Reopened(g.currentBets[pid]) {
+					delete(g.acted, pid)
+				}
+			}
+		}
 	}
-	entry := fmt.Sprintf("%s%s%d,%d", id, code, amount, time.Now().Unix())
-	g.ActionLog = append(g.ActionLog, entry)
+	g.acted[playerID] = true
+
+	g.logActionNoLock(playerID, code, amount)
+	g.updateBettingNoLock()
+	g.advanceActionNoLock(g.actionOn)
 	return nil
 }
 
@@ -367,32 +682,12 @@ func (g *Game) ActionStrings() []string {
 	defer g.mu.RUnlock()
 	lines := make([]string, len(g.ActionLog))
 	for i, raw := range g.ActionLog {
-		parts := strings.SplitN(raw, ",", 2)
-		if len(parts) != 2 {
+		a, err := ParseAction(raw)
+		if err != nil {
 			lines[i] = raw
 			continue
 		}
-		ts, _ := strconv.ParseInt(parts[1], 10, 64)
-		body := parts[0]
-		if strings.HasPrefix(body, "G:") {
-			fields := strings.Split(body[2:], ":")
-			if len(fields) >= 5 {
-				lines[i] = fmt.Sprintf("start sb=%s bb=%s ante=%s runTwice=%s straddle=%s at %s", fields[0], fields[1], fields[2], fields[3], fields[4], time.Unix(ts, 0).Format(time.RFC3339))
-				continue
-			}
-		} else if strings.HasPrefix(body, "E:") {
-			fields := strings.Split(body[2:], ":")
-			lines[i] = fmt.Sprintf("result %v at %s", fields, time.Unix(ts, 0).Format(time.RFC3339))
-			continue
-		} else if len(body) >= 9 {
-			pid := body[:8]
-			code := string(body[8])
-			amt := body[9:]
-			word := ActionToWord(code)
-			lines[i] = fmt.Sprintf("%s %s %s at %s", pid, word, amt, time.Unix(ts, 0).Format(time.RFC3339))
-			continue
-		}
-		lines[i] = raw
+		lines[i] = a.String()
 	}
 	return lines
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"runtime"
 	"sync"
 	"testing"
 
@@ -78,40 +80,49 @@ func TestGameConcurrentInvalidOps(t *testing.T) {
 	if err := g.Start(); err != nil {
 		t.Fatalf("start game: %v", err)
 	}
-
-	// Concurrent actions while the game is running.
-	actionFuncs := []struct {
-		pid       uuid.UUID
-		code      string
-		amount    int64
-		expectErr bool
-	}{
-		{p1, ActionCheck, 0, false},
-		{p2, ActionRaise, 200, false},
-		{p3, ActionFold, 0, false},
-		{missing, ActionRaise, 100, true},
+	if hands := g.DealHands(); len(hands) != 3 {
+		t.Fatalf("expected 3 hands got %d", len(hands))
 	}
 
-	wg.Add(len(actionFuncs))
-	for _, a := range actionFuncs {
-		go func(ac struct {
-			pid       uuid.UUID
-			code      string
-			amount    int64
-			expectErr bool
-		}) {
+	// Concurrent actions while the game is running. Every player tries to
+	// act from its own goroutine; turn order serializes them so each player
+	// calls or checks exactly once and out-of-turn attempts are rejected.
+	wg.Add(3)
+	for _, p := range []uuid.UUID{p1, p2, p3} {
+		go func(pid uuid.UUID) {
 			defer wg.Done()
-			err := g.AddAction(ac.pid, ac.code, ac.amount)
-			if ac.expectErr && err == nil {
-				t.Errorf("expected error for player %s", ac.pid)
-			}
-			if !ac.expectErr && err != nil {
-				t.Errorf("unexpected error for player %s: %v", ac.pid, err)
+			for !g.BettingClosed() {
+				var legal LegalAction
+				for _, a := range g.LegalActions() {
+					if a.Code == ActionCheck {
+						legal = a
+					}
+				}
+				err := g.AddAction(pid, ActionCheck, legal.Max)
+				if err == nil {
+					return
+				}
+				var turnErr *TurnError
+				if !errors.As(err, &turnErr) && !g.BettingClosed() {
+					t.Errorf("unexpected error for player %s: %v", pid, err)
+					return
+				}
+				runtime.Gosched()
 			}
-		}(a)
+		}(p)
 	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := g.AddAction(missing, ActionRaise, 100); err == nil {
+			t.Errorf("expected error for player %s", missing)
+		}
+	}()
 	wg.Wait()
 
+	if !g.BettingClosed() {
+		t.Fatal("betting should be closed after every player acted")
+	}
 	if err := g.End(); err != nil {
 		t.Fatalf("end game: %v", err)
 	}
@@ -23,7 +23,8 @@ func TestActionStringsReadable(t *testing.T) {
 	if err := g.Start(); err != nil {
 		t.Fatalf("start: %v", err)
 	}
-	if err := g.AddAction(p1, ActionCheck, 0); err != nil {
+	g.DealHands()
+	if err := g.AddAction(p1, ActionCheck, 100); err != nil {
 		t.Fatalf("action1: %v", err)
 	}
 	if err := g.AddAction(p2, ActionRaise, 200); err != nil {
@@ -34,14 +35,26 @@ func TestActionStringsReadable(t *testing.T) {
 	}
 
 	lines := g.ActionStrings()
-	if len(lines) < 5 {
-		t.Fatalf("expected at least 5 lines got %d", len(lines))
+	if len(lines) < 9 {
+		t.Fatalf("expected at least 9 lines got %d", len(lines))
 	}
-	if !containsWord(lines[3], "check") {
-		t.Errorf("expected check action, got %s", lines[3])
+	if !containsWord(lines[0], "log version 2") {
+		t.Errorf("expected version header, got %s", lines[0])
 	}
-	if !containsWord(lines[4], "raise") {
-		t.Errorf("expected raise action, got %s", lines[4])
+	if !containsWord(lines[4], "deal preflop") {
+		t.Errorf("expected preflop deal, got %s", lines[4])
+	}
+	if !containsWord(lines[5], "small-blind 50") {
+		t.Errorf("expected small blind, got %s", lines[5])
+	}
+	if !containsWord(lines[6], "big-blind 100") {
+		t.Errorf("expected big blind, got %s", lines[6])
+	}
+	if !containsWord(lines[7], "check") {
+		t.Errorf("expected check action, got %s", lines[7])
+	}
+	if !containsWord(lines[8], "raise") {
+		t.Errorf("expected raise action, got %s", lines[8])
 	}
 }
 
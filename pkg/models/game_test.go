@@ -85,6 +85,7 @@ func TestAddActionInsufficientChips(t *testing.T) {
 	if err := g.Start(); err != nil {
 		t.Fatalf("start: %v", err)
 	}
+	g.DealHands()
 	if err := g.AddAction(p1, ActionRaise, 200); err == nil {
 		t.Fatal("expected error on insufficient chips")
 	}
@@ -2,10 +2,22 @@ package models
 
 import "github.com/google/uuid"
 
-// Ledger stores the final balance of a player after a game.
+// HouseID is the player ID of the house ledger row holding the rake and
+// fees of a game. It is written as HousePlayer in the end entry.
+var HouseID = uuid.Nil
+
+// HousePlayer names the house in the end entry.
+const HousePlayer = "house"
+
+// Ledger stores the final balance of a player after a game together with
+// the chips the player bought in for, split into the initial or carried
+// buy-in, rebuys and top-ups.
 type Ledger struct {
 	ID       uuid.UUID `gorm:"type:uuid;primary_key"`
 	GameID   uuid.UUID `gorm:"type:uuid;index"`
 	PlayerID uuid.UUID `gorm:"type:uuid"`
 	Balance  int64
+	BuyIn    int64
+	Rebuys   int64
+	TopUps   int64
 }
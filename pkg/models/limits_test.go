@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"pokerDB/pkg/rules/betting"
+)
+
+// withLimits bets under the limits.
+func withLimits(limits betting.Limits) gameOption {
+	return func(g *Game, _ []uuid.UUID) {
+		g.Betting = limits
+	}
+}
+
+// raiseRange returns the raise and all-in actions available to the player
+// to act.
+func raiseRange(g *Game) (raise, allIn *LegalAction) {
+	for _, a := range g.LegalActions() {
+		a := a
+		switch a.Code {
+		case ActionRaise:
+			raise = &a
+		case ActionAllIn:
+			allIn = &a
+		}
+	}
+	return raise, allIn
+}
+
+func TestPotLimitRaises(t *testing.T) {
+	g, players := newStartedGame(t, withLimits(betting.Limits{Structure: betting.PotLimit}), 1000, 1000, 1000)
+	g.DealHands()
+	// button is player 1, player 2 posts the small blind, player 3 the big blind
+	raise, allIn := raiseRange(g)
+	if raise == nil || raise.Min != 200 || raise.Max != 350 {
+		t.Fatalf("expected a raise from 200 to 350 got %+v", raise)
+	}
+	if allIn != nil {
+		t.Fatalf("expected no all-in above the pot got %+v", allIn)
+	}
+	if err := g.AddAction(players[0], ActionRaise, 400); !errors.Is(err, betting.ErrRaiseTooLarge) {
+		t.Fatalf("expected raise too large got %v", err)
+	}
+	if err := g.AddAction(players[0], ActionAllIn, 1000); !errors.Is(err, betting.ErrRaiseTooLarge) {
+		t.Fatalf("expected all-in too large got %v", err)
+	}
+	if err := g.AddAction(players[0], ActionRaise, 350); err != nil {
+		t.Fatalf("pot raise: %v", err)
+	}
+	// calling 300 makes the pot 800, a raise to 1150 is more than the
+	// small blind holds
+	if raise, _ := raiseRange(g); raise == nil || raise.Max != 1000 {
+		t.Fatalf("expected the small blind to raise up to the stack got %+v", raise)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 350); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	// calling 250 makes the pot 1050, so the big blind may raise to 1400
+	// but only holds 1000
+	if _, allIn := raiseRange(g); allIn == nil || allIn.Max != 1000 {
+		t.Fatalf("expected an all-in within the pot got %+v", allIn)
+	}
+}
+
+func TestFixedLimitRaises(t *testing.T) {
+	limits := betting.Limits{Structure: betting.FixedLimit, RaiseCap: 3}
+	g, players := newStartedGame(t, withLimits(limits), 1000, 1000, 1000)
+	g.DealHands()
+	raise, _ := raiseRange(g)
+	if raise == nil || raise.Min != 200 || raise.Max != 200 {
+		t.Fatalf("expected a raise to exactly 200 got %+v", raise)
+	}
+	if err := g.AddAction(players[0], ActionRaise, 300); !errors.Is(err, betting.ErrRaiseTooLarge) {
+		t.Fatalf("expected raise too large got %v", err)
+	}
+	if err := g.AddAction(players[0], ActionRaise, 200); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionRaise, 300); err != nil {
+		t.Fatalf("re-raise: %v", err)
+	}
+	// the big blind, the raise and the re-raise reach the cap of three
+	if raise, allIn := raiseRange(g); raise != nil || allIn != nil {
+		t.Fatalf("expected no raise after the cap got %+v %+v", raise, allIn)
+	}
+	if err := g.AddAction(players[2], ActionRaise, 400); !errors.Is(err, betting.ErrRaiseCapped) {
+		t.Fatalf("expected raises capped got %v", err)
+	}
+	for _, p := range []uuid.UUID{players[2], players[0]} {
+		if err := g.AddAction(p, ActionCheck, 300); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+	// the flop is bet in small bets, the turn and river in big bets
+	for _, street := range []int64{100, 200, 200} {
+		if _, err := g.NextStreet(); err != nil {
+			t.Fatalf("next street: %v", err)
+		}
+		raise, _ := raiseRange(g)
+		if raise == nil || raise.Min != street || raise.Max != street {
+			t.Fatalf("expected a bet of exactly %d on the %s got %+v", street, g.CurrentStreet, raise)
+		}
+		for !g.BettingClosed() {
+			checkOrCall(t, g)
+		}
+	}
+
+	replayed, err := Replay(g.ActionLog, g.CardSequence)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.Betting != limits {
+		t.Fatalf("expected the limits to be replayed got %+v", replayed.Betting)
+	}
+}
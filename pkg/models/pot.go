@@ -0,0 +1,119 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"sort"
+)
+
+// Pot is an amount of chips together with the players who can win it. The
+// first pot returned by Game.Pots is the main pot and any following pots are
+// side pots created by all-in players. Rake is the part of Amount the house
+// takes under the game's rake rules if the hand is awarded in its current
+// state; the winners share the rest.
+type Pot struct {
+	Amount   int64       `json:"amount"`
+	Eligible []uuid.UUID `json:"eligible"`
+	Rake     int64       `json:"rake,omitempty"`
+}
+
+// Pots returns the main pot and side pots of the current hand built from
+// every chip put in so far, including antes, blinds and bets on the current
+// street.
+func (g *Game) Pots() []Pot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.potsNoLock()
+}
+
+// PotTotal returns the number of chips put into the current hand.
+func (g *Game) PotTotal() int64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.potTotalNoLock()
+}
+
+func (g *Game) potTotalNoLock() int64 {
+	var total int64
+	for _, c := range g.contributed {
+		total += c
+	}
+	return total
+}
+
+// potsNoLock splits the contributions of the hand into pots. Every distinct
+// amount put in by a player who has not folded caps one pot; folded players'
+// chips count towards the pots but they are not eligible to win them. Each
+// pot carries the rake due on it. The caller must hold the mutex.
+func (g *Game) potsNoLock() []Pot {
+	live := g.livePlayersNoLock()
+	levels := []int64{}
+	seen := make(map[int64]bool)
+	for _, pid := range live {
+		if c := g.contributed[pid]; c > 0 && !seen[c] {
+			seen[c] = true
+			levels = append(levels, c)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+	pots := []Pot{}
+	var prev int64
+	for i, level := range levels {
+		pot := Pot{Eligible: []uuid.UUID{}}
+		for _, pid := range g.inHand {
+			c := g.contributed[pid]
+			if i < len(levels)-1 && c > level {
+				c = level
+			}
+			if c > prev {
+				pot.Amount += c - prev
+			}
+		}
+		for _, pid := range live {
+			if g.contributed[pid] >= level {
+				pot.Eligible = append(pot.Eligible, pid)
+			}
+		}
+		pots = append(pots, pot)
+		prev = level
+	}
+	if len(pots) == 0 {
+		if total := g.potTotalNoLock(); total > 0 {
+			pots = append(pots, Pot{Amount: total, Eligible: live})
+		}
+	}
+	amounts := make([]int64, len(pots))
+	for i, pot := range pots {
+		amounts[i] = pot.Amount
+	}
+	for i, r := range g.Rake.Take(amounts, len(g.inHand), len(g.Board) > 0) {
+		pots[i].Rake = r
+	}
+	return pots
+}
+
+// returnUncalledNoLock gives back the part of the highest bet on the street
+// that nobody matched once betting closes. The caller must hold the mutex.
+func (g *Game) returnUncalledNoLock() {
+	var top uuid.UUID
+	var highest, second int64
+	for _, pid := range g.inHand {
+		bet := g.currentBets[pid]
+		if bet > highest {
+			top, second, highest = pid, highest, bet
+		} else if bet > second {
+			second = bet
+		}
+	}
+	excess := highest - second
+	if excess <= 0 {
+		return
+	}
+	g.currentBets[top] -= excess
+	g.contributed[top] -= excess
+	g.betRound.CurrentBet = g.currentBets[top]
+	if _, ok := g.Stacks[top]; ok {
+		g.Stacks[top] += excess
+	}
+	g.logActionNoLock(top, ActionUncalled, excess)
+}
@@ -0,0 +1,88 @@
+package models
+
+import "testing"
+
+func TestSidePots(t *testing.T) {
+	g, players := newStartedGame(t, nil, 100, 300, 500)
+	g.DealHands()
+	// button is player 1, player 2 posts the small blind, player 3 the big blind
+	if err := g.AddAction(players[0], ActionAllIn, 100); err != nil {
+		t.Fatalf("all-in p1: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionAllIn, 300); err != nil {
+		t.Fatalf("all-in p2: %v", err)
+	}
+	if err := g.AddAction(players[2], ActionCheck, 300); err != nil {
+		t.Fatalf("call p3: %v", err)
+	}
+	pots := g.Pots()
+	if len(pots) != 2 {
+		t.Fatalf("expected main and side pot got %+v", pots)
+	}
+	if pots[0].Amount != 300 || len(pots[0].Eligible) != 3 {
+		t.Errorf("unexpected main pot %+v", pots[0])
+	}
+	if pots[1].Amount != 400 || len(pots[1].Eligible) != 2 || pots[1].Eligible[0] != players[1] {
+		t.Errorf("unexpected side pot %+v", pots[1])
+	}
+	if g.PotTotal() != 700 {
+		t.Errorf("expected 700 in the pot got %d", g.PotTotal())
+	}
+}
+
+func TestUncalledBetReturned(t *testing.T) {
+	g, players := newStartedGame(t, nil, 100, 300, 500)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionAllIn, 100); err != nil {
+		t.Fatalf("all-in p1: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionAllIn, 300); err != nil {
+		t.Fatalf("all-in p2: %v", err)
+	}
+	if err := g.AddAction(players[2], ActionAllIn, 500); err != nil {
+		t.Fatalf("all-in p3: %v", err)
+	}
+	if !g.BettingClosed() {
+		t.Fatal("betting should be closed")
+	}
+	if g.Stacks[players[2]] != 200 {
+		t.Fatalf("expected 200 returned got stack %d", g.Stacks[players[2]])
+	}
+	last, err := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if err != nil || last.Player != players[2].String() || last.Code != ActionUncalled || last.Amount != 200 {
+		t.Fatalf("expected uncalled entry got %s", g.ActionLog[len(g.ActionLog)-1])
+	}
+	if g.PotTotal() != 700 {
+		t.Fatalf("expected 700 in the pot got %d", g.PotTotal())
+	}
+}
+
+func TestPotsAcrossStreetsWithFold(t *testing.T) {
+	g, players := newStartedGame(t, nil, 1000, 1000, 1000)
+	g.DealHands()
+	for !g.BettingClosed() {
+		checkOrCall(t, g)
+	}
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionRaise, 200); err != nil {
+		t.Fatalf("bet: %v", err)
+	}
+	if err := g.AddAction(players[2], ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.AddAction(players[0], ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	pots := g.Pots()
+	if len(pots) != 1 || pots[0].Amount != 300 {
+		t.Fatalf("expected single pot of 300 got %+v", pots)
+	}
+	if len(pots[0].Eligible) != 1 || pots[0].Eligible[0] != players[1] {
+		t.Fatalf("only the last player should be eligible got %v", pots[0].Eligible)
+	}
+	if g.Stacks[players[1]] != 900 {
+		t.Fatalf("uncalled flop bet should be returned, stack %d", g.Stacks[players[1]])
+	}
+}
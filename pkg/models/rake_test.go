@@ -0,0 +1,128 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"pokerDB/pkg/rules/rake"
+)
+
+// withRake rakes the game under the rules.
+func withRake(rules rake.Rules) gameOption {
+	return func(g *Game, _ []uuid.UUID) {
+		g.Rake = rules
+	}
+}
+
+// betAndFold calls preflop, deals the flop and has the first player to act
+// bet 100 into a fold. It returns the player who won the pot.
+func betAndFold(t *testing.T, g *Game) uuid.UUID {
+	t.Helper()
+	checkOrCall(t, g)
+	checkOrCall(t, g)
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	winner := g.ActionOn()
+	if err := g.AddAction(winner, ActionRaise, 100); err != nil {
+		t.Fatalf("bet: %v", err)
+	}
+	if err := g.AddAction(g.ActionOn(), ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	return winner
+}
+
+func TestRakeTakenAtAward(t *testing.T) {
+	g, players := newStartedGame(t, withRake(rake.Rules{Percent: 1000, Cap: 15, Fee: 5}), 500, 500)
+	g.DealHands()
+	winner := betAndFold(t, g)
+	pots := g.Pots()
+	if len(pots) != 1 || pots[0].Amount != 200 || pots[0].Rake != 15 {
+		t.Fatalf("expected 200 pot raked 15 got %+v", pots)
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{winner: 200}}); err == nil {
+		t.Fatal("expected error awarding the rake")
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{winner: 185}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	last, err := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if err != nil || last.Kind != EntryRake || last.Amount != 15 {
+		t.Fatalf("expected rake entry got %s", g.ActionLog[len(g.ActionLog)-1])
+	}
+	if g.HouseBalance() != 25 {
+		t.Fatalf("expected 15 rake and 10 in fees got %d", g.HouseBalance())
+	}
+	balances := g.Balances()
+	if balances[players[0]]+balances[players[1]] != -25 {
+		t.Fatalf("expected players down the house balance got %v", balances)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	var house *Ledger
+	for i, l := range g.Ledgers {
+		if l.PlayerID == HouseID {
+			house = &g.Ledgers[i]
+		}
+	}
+	if house == nil || house.Balance != 25 {
+		t.Fatalf("expected house ledger of 25 got %+v", g.Ledgers)
+	}
+	end, err := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if err != nil || len(end.Balances) != 3 || end.Balances[2] != (LedgerBalance{Player: HousePlayer, Balance: 25}) {
+		t.Fatalf("expected house balance in the end entry got %s", g.ActionLog[len(g.ActionLog)-1])
+	}
+
+	replayed, err := Replay(g.ActionLog, g.CardSequence)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.HouseBalance() != 25 || !reflect.DeepEqual(replayed.Rake, g.Rake) {
+		t.Fatalf("replay lost the rake: house %d rules %+v", replayed.HouseBalance(), replayed.Rake)
+	}
+}
+
+func TestRakeNoFlopNoDrop(t *testing.T) {
+	g, _ := newStartedGame(t, withRake(rake.Rules{Percent: 1000, NoFlopNoDrop: true}), 500, 500)
+	g.DealHands()
+	winner := g.ActionOn()
+	if err := g.AddAction(winner, ActionRaise, 300); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(g.ActionOn(), ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if pots := g.Pots(); len(pots) != 1 || pots[0].Rake != 0 {
+		t.Fatalf("expected no rake before the flop got %+v", pots)
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{winner: 200}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if g.HouseBalance() != 0 {
+		t.Fatalf("expected nothing for the house got %d", g.HouseBalance())
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	for _, l := range g.Ledgers {
+		if l.PlayerID == HouseID {
+			t.Fatalf("unexpected house ledger %+v", l)
+		}
+	}
+}
+
+func TestRakeInvalid(t *testing.T) {
+	g := NewGame(uuid.New(), 2)
+	g.Rake = rake.Rules{Percent: 20000}
+	for _, p := range []uuid.UUID{uuid.New(), uuid.New()} {
+		if err := g.BuyIn(p, 100); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != rake.ErrBadPercent {
+		t.Fatalf("expected ErrBadPercent got %v", err)
+	}
+}
@@ -0,0 +1,542 @@
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReplayError describes the log entry a replay stopped at.
+type ReplayError struct {
+	Index int    // zero-based index of the entry in the log
+	Entry string // raw action log entry
+	Err   error  // underlying error
+}
+
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("replay entry %d %q: %v", e.Index, e.Entry, e.Err)
+}
+
+// Unwrap returns the underlying error so callers can match it with errors.Is.
+func (e *ReplayError) Unwrap() error {
+	return e.Err
+}
+
+// ReplayGame rebuilds the in-memory state of a game loaded from storage from
+// its action log and card sequence. The players are taken from the saved
+// buy-ins, and the identifiers, buy-in limits and shuffler are copied over.
+func ReplayGame(saved *Game) (*Game, error) {
+	saved.mu.RLock()
+	log := append(ActionLog{}, saved.ActionLog...)
+	cards := append(IntSlice{}, saved.CardSequence...)
+	players := make([]uuid.UUID, 0, len(saved.BuyIns))
+	for _, b := range saved.BuyIns {
+		players = append(players, b.PlayerID)
+	}
+	id, tableID := saved.ID, saved.TableID
+	shuffler, fair := saved.Shuffler, saved.fair
+	minBuyIn, maxBuyIn := saved.MinBuyIn, saved.MaxBuyIn
+	saved.mu.RUnlock()
+
+	g, err := Replay(log, cards, players...)
+	if err != nil {
+		return nil, err
+	}
+	g.ID = id
+	g.TableID = tableID
+	g.MinBuyIn = minBuyIn
+	g.MaxBuyIn = maxBuyIn
+	g.Shuffler = shuffler
+	if g.fair == nil {
+		// a fair shuffle still running keeps its seed for the end entry
+		g.fair = fair
+	}
+	for k := range g.Ledgers {
+		g.Ledgers[k].GameID = id
+	}
+	return g, nil
+}
+
+// Replay rebuilds a game by feeding every entry of log back through the
+// engine with the recorded card sequence. Entries the engine writes by
+// itself, such as blinds, uncalled bets and deals, must match the log, so a
+// log that does not fit the cards or the rules is rejected with a
+// *ReplayError. The log may stop at any point, for example in the middle of
+// a hand, and the game is left in the state it had after the last entry.
+// Logs of either format are accepted and the game keeps writing in the
+// format of its log. players lists the full IDs of the players so the
+// truncated IDs of version 1 logs can be resolved; version 2 logs name the
+// players in full and need none. Version 1 logs written before blinds and
+// streets were logged are replayed by replayLegacy. The returned game holds
+// a copy of log as its action log.
+func Replay(log ActionLog, cardSequence IntSlice, players ...uuid.UUID) (*Game, error) {
+	r := &replayer{
+		g:       NewGame(uuid.Nil, 0),
+		log:     log,
+		cards:   cardSequence,
+		players: make(map[string]uuid.UUID),
+	}
+	for _, p := range players {
+		r.players[shortID(p)] = p
+	}
+	r.g.logVersion = log.Version()
+	if r.g.logVersion == LogV1 && !hasStreets(log) {
+		return r.replayLegacy()
+	}
+	for _, entry := range log {
+		// buy-ins before the start entry already pay the fee it records
+		if a, err := ParseAction(entry); err == nil && a.Kind == EntryStart {
+			r.g.Rake = a.Start.Rake
+			break
+		}
+	}
+	for i := 0; i < len(log); i++ {
+		if i >= len(r.g.ActionLog) {
+			if err := r.apply(i); err != nil {
+				return nil, &ReplayError{Index: i, Entry: log[i], Err: err}
+			}
+		}
+		if i >= len(r.g.ActionLog) {
+			return nil, &ReplayError{Index: i, Entry: log[i], Err: errors.New("entry not reproduced")}
+		}
+		if got := entryBody(r.g.ActionLog[i]); got != entryBody(log[i]) {
+			return nil, &ReplayError{Index: i, Entry: log[i], Err: fmt.Errorf("engine logged %q", got)}
+		}
+	}
+	if len(r.g.ActionLog) > len(log) {
+		return nil, fmt.Errorf("replay logged %d entries, log holds %d", len(r.g.ActionLog), len(log))
+	}
+	r.g.ActionLog = append(ActionLog{}, log...)
+	if _, ok := r.g.Shuffler.(*sequenceShuffler); ok {
+		// hands dealt after the replay are shuffled afresh
+		r.g.Shuffler = nil
+	}
+	return r.g, nil
+}
+
+// sequenceShuffler hands out the decks recorded in a card sequence in
+// order, so a replay deals every hand from the deck it was dealt from.
+type sequenceShuffler struct {
+	cards IntSlice
+}
+
+// Shuffle copies the next recorded deck into cards.
+func (s *sequenceShuffler) Shuffle(cards []int) error {
+	if len(s.cards) < len(cards) {
+		return fmt.Errorf("%w: card sequence holds no deck for the hand", ErrDeckExhausted)
+	}
+	copy(cards, s.cards)
+	s.cards = s.cards[len(cards):]
+	return nil
+}
+
+// Name returns "sequence".
+func (s *sequenceShuffler) Name() string {
+	return "sequence"
+}
+
+// hasStreets reports whether the log holds street entries, which the
+// engine writes with every hand since blinds and streets are logged.
+func hasStreets(log ActionLog) bool {
+	for _, entry := range log {
+		if a, err := ParseAction(entry); err == nil && a.Kind == EntryStreet {
+			return true
+		}
+	}
+	return false
+}
+
+// replayLegacy rebuilds a game from a version 1 log written before blinds
+// and streets were logged. Such logs only record buy-ins, the start, the
+// players' bets and seat changes and the end, and hands were not tracked,
+// so the entries are applied the way the engine of the time did instead of
+// being fed back through the rules: every bet takes the difference to the
+// player's previous bet from the stack. The game is restored with its
+// stacks, seats and ledgers but holds no hand that could be continued.
+func (r *replayer) replayLegacy() (*Game, error) {
+	g := r.g
+	bets := make(map[uuid.UUID]int64)
+	for i, entry := range r.log {
+		a, err := ParseAction(entry)
+		if err == nil {
+			err = r.applyLegacy(a, bets)
+		}
+		if err != nil {
+			return nil, &ReplayError{Index: i, Entry: entry, Err: err}
+		}
+	}
+	g.ActionLog = append(ActionLog{}, r.log...)
+	return g, nil
+}
+
+// applyLegacy applies an entry of a version 1 log without streets. bets
+// holds every player's bet since the start.
+func (r *replayer) applyLegacy(a Action, bets map[uuid.UUID]int64) error {
+	g := r.g
+	switch a.Kind {
+	case EntryStart:
+		if !g.StartedTime.IsZero() {
+			return errors.New("game already started")
+		}
+		s := a.Start
+		g.SmallBlind, g.BigBlind, g.Ante = s.SmallBlind, s.BigBlind, s.Ante
+		g.AllowRunItTwice = s.RunItTwice
+		g.AllowStraddle = s.Straddle
+		g.PersonCount = g.BuyIns.Players()
+		g.CardSequence = append(IntSlice{}, r.cards...)
+		g.StartedTime = a.Time
+		g.CurrentRound = 1
+		return nil
+	case EntryEnd:
+		if g.StartedTime.IsZero() || !g.EndedTime.IsZero() {
+			return errors.New("game not running")
+		}
+		g.Ledgers = []Ledger{}
+		for _, b := range a.Balances {
+			pid, err := r.player(b.Player)
+			if err != nil {
+				return err
+			}
+			g.Ledgers = append(g.Ledgers, Ledger{ID: uuid.New(), GameID: g.ID, PlayerID: pid, Balance: b.Balance})
+		}
+		g.EndedTime = a.Time
+		return nil
+	case EntryPlayer:
+	default:
+		return errors.New("unexpected entry in a log without streets")
+	}
+	pid, err := r.player(a.Player)
+	if err != nil {
+		return err
+	}
+	switch a.Code {
+	case ActionBuyIn:
+		g.BuyIns = append(g.BuyIns, BuyIn{PlayerID: pid, Amount: a.Amount})
+		g.Stacks[pid] += a.Amount
+	case ActionJoin:
+		if _, ok := g.Seats[pid]; ok {
+			return fmt.Errorf("player %s already joined", pid)
+		}
+		g.Seats[pid] = -1
+	case ActionQuit:
+		if _, ok := g.Seats[pid]; !ok {
+			return fmt.Errorf("unknown player %s", pid)
+		}
+		delete(g.Seats, pid)
+		delete(g.Stacks, pid)
+		delete(bets, pid)
+	case ActionSeat:
+		g.NextSeats[pid] = int(a.Amount)
+	case ActionRaise, ActionFold, ActionCheck, ActionAllIn, ActionStraddle, ActionRunTwice:
+		// AddAction took every other code as the player's bet
+		if g.StartedTime.IsZero() {
+			return errors.New("game not started")
+		}
+		stack, ok := g.Stacks[pid]
+		if !ok {
+			return fmt.Errorf("unknown player %s", pid)
+		}
+		need := a.Amount - bets[pid]
+		if need < 0 {
+			need = 0
+		}
+		if need > stack {
+			return errors.New("insufficient chips")
+		}
+		g.Stacks[pid] = stack - need
+		bets[pid] = a.Amount
+	default:
+		return fmt.Errorf("unexpected action %s", a.Code)
+	}
+	return nil
+}
+
+// replayer holds the state of a running replay.
+type replayer struct {
+	g       *Game
+	log     ActionLog
+	cards   IntSlice
+	players map[string]uuid.UUID
+}
+
+// apply feeds log entry i to the engine.
+func (r *replayer) apply(i int) error {
+	g := r.g
+	a, err := ParseAction(r.log[i])
+	if err != nil {
+		return err
+	}
+	switch a.Kind {
+	case EntryVersion:
+		if i != 0 {
+			return errors.New("version header inside the log")
+		}
+		g.mu.Lock()
+		g.ActionLog = append(g.ActionLog, r.log[i])
+		g.unlock()
+		return nil
+	case EntryStart:
+		return r.start(a.Start, a.Time, i)
+	case EntryStreet:
+		return r.deal(a.Street, i)
+	case EntryEnd:
+		return r.end(a.Balances, a.Seed, a.Time)
+	case EntryRake:
+		return errors.New("rake not taken by the engine")
+	}
+	pid, err := r.player(a.Player)
+	if err != nil {
+		return err
+	}
+	switch a.Code {
+	case ActionBuyIn, ActionRebuy, ActionTopUp:
+		return g.BuyIn(pid, a.Amount)
+	case ActionJoin:
+		return g.Join(pid)
+	case ActionQuit:
+		return g.Quit(pid)
+	case ActionSeat:
+		if !g.Started() {
+			// seats logged before the start are taken in this game
+			g.mu.Lock()
+			g.Seats[pid] = int(a.Amount)
+			g.ActionLog = append(g.ActionLog, r.log[i])
+			g.unlock()
+			return nil
+		}
+		return g.ChooseSeat(pid, int(a.Amount))
+	case ActionRunTwice:
+		return g.RunItTwice(pid, int(a.Amount))
+	case ActionSitOut:
+		if !g.Started() {
+			// players sitting out in the previous game keep their missed
+			// blinds
+			g.mu.Lock()
+			defer g.unlock()
+			return g.sitOutCarriedNoLock(pid, int(a.Amount))
+		}
+		return g.SitOut(pid)
+	case ActionSitIn:
+		return g.SitIn(pid, a.Amount == 1)
+	case ActionWin:
+		return r.award(i)
+	case ActionRaise, ActionFold, ActionCheck, ActionAllIn, ActionTimeBank:
+		g.mu.RLock()
+		deal := g.inRound && g.CurrentStreet == StreetPreflop && !g.holeDealt
+		g.mu.RUnlock()
+		if deal {
+			// hole cards are dealt right before the first action
+			if _, err := g.DealHoleCards(); err != nil {
+				return err
+			}
+		}
+		if a.Code == ActionTimeBank {
+			return g.UseTimeBank(pid, time.Duration(a.Amount)*time.Millisecond)
+		}
+		return g.AddAction(pid, a.Code, a.Amount)
+	}
+	return fmt.Errorf("unexpected action %s", a.Code)
+}
+
+// start applies a start entry.
+func (r *replayer) start(s *GameStart, at time.Time, i int) error {
+	g := r.g
+	g.mu.Lock()
+	if !g.StartedTime.IsZero() {
+		g.unlock()
+		return errors.New("game already started")
+	}
+	g.SmallBlind, g.BigBlind, g.Ante = s.SmallBlind, s.BigBlind, s.Ante
+	g.AllowRunItTwice = s.RunItTwice
+	g.AllowStraddle = s.Straddle
+	g.Betting = s.Betting
+	g.Variant = s.Variant
+	g.Rake = s.Rake
+	g.DecisionTime, g.TimeBank = s.DecisionTime, s.TimeBank
+	g.CurrentDealer = s.ButtonSeat
+	g.SmallBlindSeat = s.SmallBlindSeat
+	g.BigBlindSeat = s.BigBlindSeat
+	g.PersonCount = g.BuyIns.Players()
+	g.unlock()
+
+	cards := append(IntSlice{}, r.cards...)
+	if s.Deal == DealPerHand {
+		// the card sequence holds a deck for every hand
+		n := len(s.Variant.Deck())
+		if len(cards) < n {
+			return fmt.Errorf("%w: %d cards needed, %d left", ErrDeckExhausted, n, len(cards))
+		}
+		g.Shuffler = &sequenceShuffler{cards: cards[n:]}
+		cards = cards[:n:n]
+	}
+	r.straddles(i)
+	g.mu.Lock()
+	defer g.unlock()
+	g.startNoLock(cards, s, at)
+	return nil
+}
+
+// deal applies a street entry the engine did not write by itself: a new
+// hand, a street or an extra run-it-twice board.
+func (r *replayer) deal(d *StreetDeal, i int) error {
+	g := r.g
+	switch {
+	case d.Name == StreetPreflop.String():
+		g.mu.RLock()
+		inRound := g.inRound
+		g.mu.RUnlock()
+		if inRound {
+			if err := g.EndRound(); err != nil {
+				return err
+			}
+		}
+		r.straddles(i)
+		return g.StartRound()
+	case strings.HasPrefix(d.Name, "run"):
+		g.mu.Lock()
+		defer g.unlock()
+		if g.CurrentStreet != StreetShowdown {
+			return errors.New("board not complete")
+		}
+		_, err := g.dealRunsNoLock(g.agreedRunsNoLock(), len(g.Board)-len(d.Cards))
+		return err
+	}
+	_, err := g.NextStreet()
+	return err
+}
+
+// straddles volunteers the players whose straddle is posted with the hand
+// starting at entry i.
+func (r *replayer) straddles(i int) {
+	for k, entry := range r.log[i+1:] {
+		a, err := ParseAction(entry)
+		if err != nil {
+			return
+		}
+		if k == 0 && a.Kind == EntryStreet && a.Street.Name == StreetPreflop.String() {
+			continue
+		}
+		if a.Kind != EntryPlayer {
+			return
+		}
+		switch a.Code {
+		case ActionAnte, ActionSmallBlind, ActionBigBlind:
+		case ActionStraddle:
+			if pid, err := r.player(a.Player); err == nil {
+				r.g.Straddle(pid)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// award pays the pots of a hand from the run of win entries starting at i.
+// The entries are logged pot by pot, so they are assigned to the pots in
+// order until each pot is paid in full.
+func (r *replayer) award(i int) error {
+	wins := []Action{}
+	for _, entry := range r.log[i:] {
+		a, err := ParseAction(entry)
+		if err != nil || a.Kind != EntryPlayer || a.Code != ActionWin {
+			break
+		}
+		wins = append(wins, a)
+	}
+	pots := r.g.Pots()
+	awards := make([]map[uuid.UUID]int64, len(pots))
+	for k, pot := range pots {
+		awards[k] = make(map[uuid.UUID]int64)
+		eligible := make(map[uuid.UUID]bool)
+		for _, pid := range pot.Eligible {
+			eligible[pid] = true
+		}
+		var paid int64
+		for len(wins) > 0 && paid < pot.Amount-pot.Rake {
+			pid, err := r.player(wins[0].Player)
+			if err != nil {
+				return err
+			}
+			if !eligible[pid] || awards[k][pid] != 0 {
+				break
+			}
+			awards[k][pid] = wins[0].Amount
+			paid += wins[0].Amount
+			wins = wins[1:]
+		}
+	}
+	return r.g.AwardPots(awards)
+}
+
+// end applies the end entry together with the server seed revealed for a
+// fair shuffle. The engine settles the game, so the logged balances have to
+// match, except in version 1 logs whose ledger is taken as logged.
+func (r *replayer) end(balances []LedgerBalance, seed string, at time.Time) error {
+	g := r.g
+	if seed != "" {
+		serverSeed, err := hex.DecodeString(seed)
+		if err != nil {
+			return fmt.Errorf("bad server seed: %w", err)
+		}
+		g.mu.Lock()
+		g.fair = &FairShuffler{serverSeed: serverSeed, used: true}
+		g.unlock()
+	}
+	ledgers := []Ledger{}
+	for _, b := range balances {
+		pid, err := r.player(b.Player)
+		if err != nil {
+			return err
+		}
+		ledgers = append(ledgers, Ledger{ID: uuid.New(), PlayerID: pid, Balance: b.Balance})
+	}
+	if g.logVersion == LogV1 {
+		// version 1 logs were ended with the ledger the caller set rather
+		// than a settled one
+		g.mu.Lock()
+		if g.StartedTime.IsZero() || !g.EndedTime.IsZero() {
+			g.unlock()
+			return errors.New("game not running")
+		}
+		g.Ledgers = ledgers
+		g.finishNoLock()
+		g.unlock()
+	} else if err := g.End(); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.EndedTime = at
+	for k := range g.Ledgers {
+		g.Ledgers[k].GameID = g.ID
+	}
+	g.unlock()
+	return nil
+}
+
+// player resolves a player ID from the log, either in full or truncated, or
+// the house.
+func (r *replayer) player(id string) (uuid.UUID, error) {
+	if pid, err := uuid.Parse(id); err == nil {
+		return pid, nil
+	}
+	if id == HousePlayer {
+		return HouseID, nil
+	}
+	pid, ok := r.players[id]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("unknown player %s", id)
+	}
+	return pid, nil
+}
+
+// entryBody returns an action log entry without its timestamp.
+func entryBody(entry string) string {
+	if i := strings.LastIndex(entry, ","); i >= 0 {
+		return entry[:i]
+	}
+	return entry
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"pokerDB/pkg/constants"
+)
+
+// playReplayGame plays two hands with a straddle and returns the game with
+// the log length after the flop of the first hand was dealt. The log is
+// written in the given format version.
+func playReplayGame(t *testing.T, version int) (*Game, []uuid.UUID, int) {
+	t.Helper()
+	g, players := newStartedGame(t, func(g *Game, _ []uuid.UUID) {
+		g.logVersion = version
+		g.AllowStraddle = true
+	}, 1000, 1000, 1000)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionRaise, 300); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.AddAction(players[2], ActionCheck, 300); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	flop := len(g.ActionLog)
+	if err := g.AddAction(players[2], ActionRaise, 200); err != nil {
+		t.Fatalf("bet: %v", err)
+	}
+	if err := g.AddAction(players[0], ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[2]: 650}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if err := g.ChooseSeat(players[0], 5); err != nil {
+		t.Fatalf("seat: %v", err)
+	}
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	// the button is on seat 2 now, so player 2 is under the gun
+	if err := g.Straddle(players[1]); err != nil {
+		t.Fatalf("straddle: %v", err)
+	}
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	g.DealHands()
+	for _, p := range []uuid.UUID{players[2], players[0]} {
+		if err := g.AddAction(p, ActionFold, 0); err != nil {
+			t.Fatalf("fold: %v", err)
+		}
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[1]: 250}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	balances := g.Balances()
+	ledgers := []Ledger{}
+	for _, p := range players {
+		ledgers = append(ledgers, Ledger{ID: uuid.New(), GameID: g.ID, PlayerID: p, Balance: balances[p]})
+	}
+	g.SetLedgers(ledgers)
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	return g, players, flop
+}
+
+func TestReplay(t *testing.T) {
+	g, players, _ := playReplayGame(t, LogVersion)
+	replayed, err := Replay(g.ActionLog, g.CardSequence, players...)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !replayed.Ended() || replayed.CurrentRound != 2 {
+		t.Fatalf("expected an ended game after two rounds got round %d", replayed.CurrentRound)
+	}
+	for _, p := range players {
+		if replayed.Stacks[p] != g.Stacks[p] {
+			t.Errorf("stack %d want %d", replayed.Stacks[p], g.Stacks[p])
+		}
+		if replayed.Seats[p] != g.Seats[p] || replayed.NextSeats[p] != g.NextSeats[p] {
+			t.Errorf("seats %d %d want %d %d", replayed.Seats[p], replayed.NextSeats[p], g.Seats[p], g.NextSeats[p])
+		}
+	}
+	if replayed.CurrentDealer != g.CurrentDealer || replayed.EndedTime.Unix() != g.EndedTime.Unix() {
+		t.Fatal("button or end time not restored")
+	}
+	if len(replayed.Ledgers) != 3 || replayed.Ledgers[2].Balance != g.Ledgers[2].Balance {
+		t.Fatalf("unexpected ledgers %+v", replayed.Ledgers)
+	}
+	if len(replayed.ActionLog) != len(g.ActionLog) || replayed.ActionLog[0] != g.ActionLog[0] {
+		t.Fatal("expected the original log to be kept")
+	}
+	// version 2 logs name the players in full
+	if _, err := Replay(g.ActionLog, g.CardSequence); err != nil {
+		t.Fatalf("replay without players: %v", err)
+	}
+}
+
+func TestReplayV1(t *testing.T) {
+	g, players, flop := playReplayGame(t, LogV1)
+	if g.ActionLog.Version() != LogV1 {
+		t.Fatalf("expected a version 1 log got %s", g.ActionLog[0])
+	}
+	replayed, err := Replay(g.ActionLog[:flop], g.CardSequence, players...)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	// the restored game keeps writing version 1 entries
+	if err := replayed.AddAction(players[2], ActionRaise, 200); err != nil {
+		t.Fatalf("bet: %v", err)
+	}
+	want := shortID(players[2]) + ActionRaise + "200,"
+	if last := replayed.ActionLog[len(replayed.ActionLog)-1]; last[:len(want)] != want {
+		t.Fatalf("expected a version 1 entry got %s", last)
+	}
+	if _, err := Replay(g.ActionLog, g.CardSequence, players[:2]...); err == nil {
+		t.Fatal("expected error for an unknown player")
+	}
+}
+
+func TestReplayMidHand(t *testing.T) {
+	g, players, flop := playReplayGame(t, LogVersion)
+	replayed, err := Replay(g.ActionLog[:flop], g.CardSequence, players...)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.CurrentStreet != StreetFlop || len(replayed.Board) != 3 {
+		t.Fatalf("expected the flop got %s %v", replayed.CurrentStreet, replayed.Board)
+	}
+	if replayed.ActionOn() != players[2] || replayed.PotTotal() != 650 {
+		t.Fatalf("unexpected state: action on %s pot %d", replayed.ActionOn(), replayed.PotTotal())
+	}
+	// the restored game carries on like the original
+	if err := replayed.AddAction(players[2], ActionRaise, 200); err != nil {
+		t.Fatalf("bet: %v", err)
+	}
+	if replayed.ActionOn() != players[0] {
+		t.Fatal("expected action on the raiser")
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	g, players, _ := playReplayGame(t, LogVersion)
+	cards := append(IntSlice{}, g.CardSequence...)
+	// swap the first flop card, dealt after the hole cards and a burn card,
+	// with the bottom card of the first hand's deck
+	flop := len(players)*2 + 1
+	bottom := len(constants.CardSequence) - 1
+	cards[flop], cards[bottom] = cards[bottom], cards[flop]
+	_, err := Replay(g.ActionLog, cards, players...)
+	var replayErr *ReplayError
+	if !errors.As(err, &replayErr) {
+		t.Fatalf("expected a replay error for another deck got %v", err)
+	}
+}
+
+func TestReplayGame(t *testing.T) {
+	g, _, _ := playReplayGame(t, LogVersion)
+	replayed, err := ReplayGame(g)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.ID != g.ID || replayed.Ledgers[0].GameID != g.ID {
+		t.Fatal("expected the game ID to be kept")
+	}
+}
+
+func TestReplayNextGame(t *testing.T) {
+	table := &Table{ID: uuid.New()}
+	g, players, _ := playReplayGame(t, LogVersion)
+	g.TableID = table.ID
+	next, err := table.NextGame(g)
+	if err != nil {
+		t.Fatalf("next game: %v", err)
+	}
+	if err := next.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	replayed, err := Replay(next.ActionLog, next.CardSequence, players...)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.Seats[players[0]] != 5 || replayed.BigBlindSeat != next.BigBlindSeat || replayed.CurrentDealer != next.CurrentDealer {
+		t.Fatalf("expected seats and button to be restored got %v button %d", replayed.Seats, replayed.CurrentDealer)
+	}
+}
+
+func TestReplayLegacyV1(t *testing.T) {
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	a, b := shortID(players[0]), shortID(players[1])
+	// a log as written before blinds and streets were logged: bets are the
+	// player's total since the start and nothing marks the streets
+	log := ActionLog{
+		a + "J0,1692300000",
+		b + "J0,1692300000",
+		a + "B1000,1692300001",
+		b + "B1000,1692300001",
+		"G:50:100:0:0:0,1692300002",
+		a + "R100,1692300010",
+		b + "C100,1692300011",
+		a + "R300,1692300020",
+		b + "F0,1692300021",
+		b + "H4,1692300030",
+		"E:" + a + "=100:" + b + "=-100,1692300040",
+	}
+	g, err := Replay(log, IntSlice(constants.CardSequence), players...)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !g.Ended() || g.BigBlind != 100 || g.StartedTime.Unix() != 1692300002 {
+		t.Fatalf("unexpected game %+v", g)
+	}
+	if g.Stacks[players[0]] != 700 || g.Stacks[players[1]] != 900 {
+		t.Fatalf("unexpected stacks %v", g.Stacks)
+	}
+	if g.NextSeats[players[1]] != 4 {
+		t.Fatalf("expected the seat choice restored got %v", g.NextSeats)
+	}
+	if len(g.Ledgers) != 2 || g.Ledgers[0].PlayerID != players[0] || g.Ledgers[0].Balance != 100 {
+		t.Fatalf("unexpected ledgers %+v", g.Ledgers)
+	}
+	if len(g.ActionLog) != len(log) {
+		t.Fatal("expected the original log to be kept")
+	}
+
+	log[7] = a + "R2000,1692300020"
+	var replayErr *ReplayError
+	if _, err := Replay(log, IntSlice(constants.CardSequence), players...); !errors.As(err, &replayErr) || replayErr.Index != 7 {
+		t.Fatalf("expected the bet above the stack rejected got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// MaxRuns is the largest number of boards a hand can be run out on.
+const MaxRuns = 4
+
+// RunItTwice records how many times a player wants the rest of the board to
+// be run out. It is only allowed once betting is closed with at most one
+// live player able to act, so no further betting can happen. The board is
+// run more than once only when every live player picks the same number of
+// runs; otherwise it is dealt once. The choice is logged with the T code.
+func (g *Game) RunItTwice(playerID uuid.UUID, runs int) error {
+	g.mu.Lock()
+	defer g.unlock()
+	if !g.AllowRunItTwice {
+		return errors.New("run it twice not allowed")
+	}
+	if !g.inRound || !g.holeDealt {
+		return errors.New("no hand in progress")
+	}
+	if !g.isInHandNoLock(playerID) || g.folded[playerID] {
+		return fmt.Errorf("player %s not in hand", playerID)
+	}
+	if runs < 1 || runs > MaxRuns {
+		return fmt.Errorf("runs must be between 1 and %d", MaxRuns)
+	}
+	if !g.allInNoLock() {
+		return errors.New("betting still possible")
+	}
+	remaining := g.remainingBoardNoLock()
+	if remaining == 0 {
+		return errors.New("board already complete")
+	}
+	if need := g.boardNeedNoLock() * runs; need > g.deck.Remaining() {
+		return fmt.Errorf("%w: %d boards need %d cards, %d left", ErrDeckExhausted, runs, need, g.deck.Remaining())
+	}
+	if g.runs == nil {
+		g.runs = make(map[uuid.UUID]int)
+	}
+	g.runs[playerID] = runs
+	g.logActionNoLock(playerID, ActionRunTwice, int64(runs))
+	return nil
+}
+
+// Runs returns the number of boards the hand will be run out on.
+func (g *Game) Runs() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.agreedRunsNoLock()
+}
+
+// RunOut deals the rest of the board and moves the hand to showdown. When
+// the players agreed to run it more than once, the first board is dealt
+// street by street as usual and every further board is dealt from the
+// following cards of the deck, burning before each street and sharing the
+// community cards already out.
+// Extra boards are logged as D:run<n> entries holding only their new cards.
+// All boards, including the first, are returned and stored in Boards.
+func (g *Game) RunOut() ([]IntSlice, error) {
+	g.mu.Lock()
+	defer g.unlock()
+	runs := g.agreedRunsNoLock()
+	shared := len(g.Board)
+	if need := g.boardNeedNoLock() * runs; runs > 1 && need > g.deck.Remaining() {
+		return nil, fmt.Errorf("%w: %d boards need %d cards, %d left", ErrDeckExhausted, runs, need, g.deck.Remaining())
+	}
+	for g.CurrentStreet != StreetShowdown {
+		if _, err := g.nextStreetNoLock(); err != nil {
+			return nil, err
+		}
+	}
+	return g.dealRunsNoLock(runs, shared)
+}
+
+// dealRunsNoLock deals boards 2 to runs once the first board is complete,
+// each sharing the first shared community cards, and logs them. It returns
+// every board. The caller must hold the mutex.
+func (g *Game) dealRunsNoLock(runs, shared int) ([]IntSlice, error) {
+	boards := []IntSlice{append(IntSlice{}, g.Board...)}
+	streets := streetsAfter(shared)
+	for run := 2; run <= runs && len(streets) > 0; run++ {
+		cards := []int{}
+		for _, street := range streets {
+			dealt, err := g.dealBoardNoLock(boardCards[street])
+			if err != nil {
+				return nil, err
+			}
+			cards = append(cards, dealt...)
+		}
+		board := append(append(IntSlice{}, g.Board[:shared]...), cards...)
+		boards = append(boards, board)
+		g.logNoLock(Action{Kind: EntryStreet, Street: &StreetDeal{Name: "run" + strconv.Itoa(run), Cards: cards}, Time: g.nowNoLock()})
+	}
+	if len(boards) > 1 {
+		g.Boards = boards
+	}
+	return boards, nil
+}
+
+// allInNoLock reports whether betting is closed for the rest of the hand
+// because at most one live player still has chips. The caller must hold
+// the mutex.
+func (g *Game) allInNoLock() bool {
+	if !g.bettingClosed {
+		return false
+	}
+	canAct := 0
+	for _, pid := range g.livePlayersNoLock() {
+		if g.Stacks[pid] > 0 {
+			canAct++
+		}
+	}
+	return canAct <= 1 && len(g.livePlayersNoLock()) > 1
+}
+
+// boardNeedNoLock returns the number of cards, burn cards included, needed
+// to complete one board from the current one. The caller must hold the
+// mutex.
+func (g *Game) boardNeedNoLock() int {
+	need := 0
+	for _, street := range streetsAfter(len(g.Board)) {
+		need += boardCards[street]
+		if g.dealMode != DealLegacy {
+			need++
+		}
+	}
+	return need
+}
+
+// remainingBoardNoLock returns the number of community cards still to come.
+// The caller must hold the mutex.
+func (g *Game) remainingBoardNoLock() int {
+	total := 0
+	for _, n := range boardCards {
+		total += n
+	}
+	return total - len(g.Board)
+}
+
+// agreedRunsNoLock returns the number of runs every live player chose, or 1
+// when they did not all agree. The caller must hold the mutex.
+func (g *Game) agreedRunsNoLock() int {
+	live := g.livePlayersNoLock()
+	if len(live) < 2 {
+		return 1
+	}
+	runs := g.runs[live[0]]
+	for _, pid := range live[1:] {
+		if g.runs[pid] != runs {
+			return 1
+		}
+	}
+	if runs < 1 {
+		return 1
+	}
+	return runs
+}
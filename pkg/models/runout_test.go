@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunItTwiceRequiresAllIn(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 1000)
+	g.AllowRunItTwice = true
+	g.DealHands()
+	if err := g.RunItTwice(players[0], 2); err == nil {
+		t.Fatal("expected error while betting is open")
+	}
+	if err := g.AddAction(players[0], ActionAllIn, 500); err != nil {
+		t.Fatalf("all-in: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 500); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if err := g.RunItTwice(players[1], MaxRuns+1); err == nil {
+		t.Fatal("expected error for too many runs")
+	}
+	for _, p := range players {
+		if err := g.RunItTwice(p, 3); err != nil {
+			t.Fatalf("run it three times: %v", err)
+		}
+	}
+	if g.Runs() != 3 {
+		t.Fatalf("expected 3 runs got %d", g.Runs())
+	}
+	boards, err := g.RunOut()
+	if err != nil {
+		t.Fatalf("run out: %v", err)
+	}
+	if len(boards) != 3 || g.CurrentStreet != StreetShowdown {
+		t.Fatalf("expected three boards at showdown got %v", boards)
+	}
+	runs := 0
+	for _, entry := range g.ActionLog {
+		if a, err := ParseAction(entry); err == nil && a.Kind == EntryStreet && strings.HasPrefix(a.Street.Name, "run") {
+			runs++
+			if len(a.Street.Cards) != 5 {
+				t.Fatalf("expected five cards in %s", entry)
+			}
+		}
+	}
+	if runs != 2 {
+		t.Fatalf("expected two extra boards in the log got %d", runs)
+	}
+}
+
+func TestRunItTwiceNotAllowed(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionAllIn, 500); err != nil {
+		t.Fatalf("all-in: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionAllIn, 500); err != nil {
+		t.Fatalf("all-in: %v", err)
+	}
+	if err := g.RunItTwice(players[0], 2); err == nil {
+		t.Fatal("expected error when run it twice is disabled")
+	}
+}
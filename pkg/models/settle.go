@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnbalanced is returned when the players' balances and the house balance
+// of a game do not add up to zero, meaning chips were created or lost.
+var ErrUnbalanced = errors.New("ledger does not balance")
+
+// Settle returns the ledger the game is settled with when it ends: one row
+// per player who bought in, in the order of their first buy-in and
+// including players who quit, with the chips of their buy-ins, rebuys and
+// top-ups, followed by the house row when the house
+// collected rake or fees. Chips still in the pot of a hand that was not
+// awarded go back to the players who put them in. Settle fails with
+// ErrUnbalanced if the rows do not net to zero.
+func (g *Game) Settle() ([]Ledger, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.settleNoLock()
+}
+
+func (g *Game) settleNoLock() ([]Ledger, error) {
+	balances := g.balancesNoLock()
+	for pid, chips := range g.contributed {
+		balances[pid] += chips
+	}
+	house := g.houseBalanceNoLock()
+	total := house
+	for _, balance := range balances {
+		total += balance
+	}
+	if total != 0 {
+		return nil, fmt.Errorf("%w: players and house net %d", ErrUnbalanced, total)
+	}
+
+	ledgers := []Ledger{}
+	rows := make(map[uuid.UUID]int)
+	for _, b := range g.BuyIns {
+		i, ok := rows[b.PlayerID]
+		if !ok {
+			i = len(ledgers)
+			rows[b.PlayerID] = i
+			ledgers = append(ledgers, Ledger{ID: uuid.New(), GameID: g.ID, PlayerID: b.PlayerID, Balance: balances[b.PlayerID]})
+		}
+		switch {
+		case b.Rebuy:
+			ledgers[i].Rebuys += b.Amount
+		case b.TopUp:
+			ledgers[i].TopUps += b.Amount
+		default:
+			ledgers[i].BuyIn += b.Amount
+		}
+	}
+	if house > 0 {
+		ledgers = append(ledgers, Ledger{ID: uuid.New(), GameID: g.ID, PlayerID: HouseID, Balance: house})
+	}
+	return ledgers, nil
+}
+
+// refundHandNoLock returns the chips in the pot of a hand that was not
+// awarded to the players who put them in, or to what they left with if they
+// quit. The caller must hold the mutex.
+func (g *Game) refundHandNoLock() {
+	for pid, chips := range g.contributed {
+		if _, ok := g.Stacks[pid]; ok {
+			g.Stacks[pid] += chips
+		} else {
+			g.cashOutNoLock(pid, chips)
+		}
+	}
+	g.contributed = make(map[uuid.UUID]int64)
+}
+
+// cashOutNoLock records chips a player left the game with. The caller must
+// hold the mutex.
+func (g *Game) cashOutNoLock(playerID uuid.UUID, chips int64) {
+	if g.cashedOut == nil {
+		g.cashedOut = make(map[uuid.UUID]int64)
+	}
+	g.cashedOut[playerID] += chips
+}
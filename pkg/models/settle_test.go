@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEndSettlesQuitPlayers(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500, 500)
+	g.DealHands()
+	// button is player 1, player 2 posts the small blind, player 3 the big blind
+	if err := g.AddAction(players[0], ActionRaise, 300); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.AddAction(players[2], ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.AwardPots([]map[uuid.UUID]int64{{players[0]: 250}}); err != nil {
+		t.Fatalf("award: %v", err)
+	}
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	if err := g.Quit(players[1]); err != nil {
+		t.Fatalf("quit: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	want := []int64{150, -50, -100}
+	if len(g.Ledgers) != len(want) {
+		t.Fatalf("expected a ledger row per player got %+v", g.Ledgers)
+	}
+	for i, l := range g.Ledgers {
+		if l.PlayerID != players[i] || l.Balance != want[i] || l.GameID != g.ID {
+			t.Errorf("ledger %d got %+v want balance %d", i, l, want[i])
+		}
+	}
+	end, err := ParseAction(g.ActionLog[len(g.ActionLog)-1])
+	if err != nil || len(end.Balances) != 3 || end.Balances[1] != (LedgerBalance{Player: players[1].String(), Balance: -50}) {
+		t.Fatalf("unexpected end entry %s", g.ActionLog[len(g.ActionLog)-1])
+	}
+
+	// the replayed game settles the same way and rejects other balances
+	if _, err := Replay(g.ActionLog, g.CardSequence); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	log := append(ActionLog{}, g.ActionLog...)
+	log[len(log)-1] = strings.Replace(log[len(log)-1], "=-50", "=-40", 1)
+	var rerr *ReplayError
+	if _, err := Replay(log, g.CardSequence); !errors.As(err, &rerr) || rerr.Index != len(log)-1 {
+		t.Fatalf("expected the end entry to be rejected got %v", err)
+	}
+}
+
+func TestEndReturnsUnawardedPot(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionRaise, 300); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.Quit(players[1]); err != nil {
+		t.Fatalf("quit: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if g.Stacks[players[0]] != 500 || g.PotTotal() != 0 {
+		t.Fatalf("expected the bet returned got stack %d pot %d", g.Stacks[players[0]], g.PotTotal())
+	}
+	for _, l := range g.Ledgers {
+		if l.Balance != 0 {
+			t.Fatalf("expected even ledgers got %+v", g.Ledgers)
+		}
+	}
+}
+
+func TestEndUnbalanced(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500)
+	g.Stacks[players[0]] += 10
+	if _, err := g.Settle(); !errors.Is(err, ErrUnbalanced) {
+		t.Fatalf("expected ErrUnbalanced got %v", err)
+	}
+	if err := g.End(); !errors.Is(err, ErrUnbalanced) {
+		t.Fatalf("expected ErrUnbalanced got %v", err)
+	}
+	if g.Ended() {
+		t.Fatal("game should still be running")
+	}
+}
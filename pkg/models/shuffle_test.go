@@ -0,0 +1,47 @@
+package models
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func startEntry(t *testing.T, g *Game) *GameStart {
+	t.Helper()
+	for _, entry := range g.ActionLog {
+		if a, err := ParseAction(entry); err == nil && a.Kind == EntryStart {
+			return a.Start
+		}
+	}
+	t.Fatalf("no start entry in %v", g.ActionLog)
+	return nil
+}
+
+func TestCryptoShufflerDefault(t *testing.T) {
+	g, _ := newStartedGame(t, nil, 1000, 1000)
+	if s := startEntry(t, g); s.Shuffler != "crypto" {
+		t.Fatalf("expected the crypto shuffler recorded got %q", s.Shuffler)
+	}
+	cards := append([]int{}, g.CardSequence...)
+	sort.Ints(cards)
+	for i, c := range cards {
+		if c != i+1 {
+			t.Fatalf("expected a permutation of the deck got %v", g.CardSequence)
+		}
+	}
+}
+
+func TestSeededShufflerReproducible(t *testing.T) {
+	a, _ := newStartedGame(t, withShuffler(NewSeededShuffler(42)), 1000, 1000)
+	b, _ := newStartedGame(t, withShuffler(NewSeededShuffler(42)), 1000, 1000)
+	if !reflect.DeepEqual(a.CardSequence, b.CardSequence) {
+		t.Fatalf("expected equal decks got %v and %v", a.CardSequence, b.CardSequence)
+	}
+	c, _ := newStartedGame(t, withShuffler(NewSeededShuffler(43)), 1000, 1000)
+	if reflect.DeepEqual(a.CardSequence, c.CardSequence) {
+		t.Fatal("expected another seed to give another deck")
+	}
+	if s := startEntry(t, a); s.Shuffler != "seeded" {
+		t.Fatalf("expected the seeded shuffler recorded got %q", s.Shuffler)
+	}
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Missed blinds, combined in the amount of a sit-out entry.
+const (
+	MissedSmallBlind = 1 << iota // missed the small blind
+	MissedBigBlind               // missed the big blind
+)
+
+// sitStatuses holds the players who sat out or are on their way back in.
+type sitStatuses map[uuid.UUID]sitStatus
+
+// sitStatus tracks a player who sat out and the blinds they missed.
+type sitStatus struct {
+	out    bool // sitting out
+	missed int  // missed blinds
+	post   bool // sat back in and posts the missed blinds
+}
+
+// SitOut keeps a seated player out of the hands dealt from the next one
+// on. The blinds the player misses meanwhile are tracked and have to be
+// made up when sitting back in. The sit-out is logged with the P code and
+// the blinds missed so far, see MissedSmallBlind and MissedBigBlind.
+func (g *Game) SitOut(playerID uuid.UUID) error {
+	g.mu.Lock()
+	defer g.unlock()
+	if _, ok := g.Seats[playerID]; !ok {
+		return fmt.Errorf("unknown player %s", playerID)
+	}
+	s := g.sitting[playerID]
+	if s.out {
+		return fmt.Errorf("player %s already sitting out", playerID)
+	}
+	g.setSitStatusNoLock(playerID, sitStatus{out: true, missed: s.missed})
+	g.logActionNoLock(playerID, ActionSitOut, int64(s.missed))
+	return nil
+}
+
+// SitIn brings a player who sat out back into the game. A player who missed
+// blinds either posts them in the next hand, when post is set, or waits
+// until the big blind reaches their seat. The missed big blind is posted
+// live and the missed small blind dead. Missed blinds are forgiven when
+// only two players are dealt in. The sit-in is logged with the I code and
+// 1 when the player posts.
+func (g *Game) SitIn(playerID uuid.UUID, post bool) error {
+	g.mu.Lock()
+	defer g.unlock()
+	s, ok := g.sitting[playerID]
+	if !ok || !s.out {
+		return fmt.Errorf("player %s not sitting out", playerID)
+	}
+	if s.missed == 0 {
+		delete(g.sitting, playerID)
+	} else {
+		g.sitting[playerID] = sitStatus{missed: s.missed, post: post}
+	}
+	g.logActionNoLock(playerID, ActionSitIn, int64(boolToInt(post)))
+	return nil
+}
+
+// SittingOut reports whether the player is sitting out or waiting for the
+// big blind after sitting back in.
+func (g *Game) SittingOut(playerID uuid.UUID) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	s, ok := g.sitting[playerID]
+	return ok && (s.out || !s.post)
+}
+
+// MissedBlinds returns the blinds the player missed while sitting out as a
+// combination of MissedSmallBlind and MissedBigBlind.
+func (g *Game) MissedBlinds(playerID uuid.UUID) int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.sitting[playerID].missed
+}
+
+func (g *Game) setSitStatusNoLock(playerID uuid.UUID, s sitStatus) {
+	if g.sitting == nil {
+		g.sitting = make(sitStatuses)
+	}
+	g.sitting[playerID] = s
+}
+
+// sitOutCarriedNoLock keeps a player who sat out in the previous game
+// sitting out together with the blinds they missed. The caller must hold
+// the mutex.
+func (g *Game) sitOutCarriedNoLock(playerID uuid.UUID, missed int) error {
+	if missed < 0 || missed > MissedSmallBlind|MissedBigBlind {
+		return errors.New("bad missed blinds")
+	}
+	g.setSitStatusNoLock(playerID, sitStatus{out: true, missed: missed})
+	g.logActionNoLock(playerID, ActionSitOut, int64(missed))
+	return nil
+}
+
+// seatReturningNoLock moves the button for a new hand and takes the players
+// waiting for the big blind back out of it unless the big blind is theirs.
+// A player returning to a hand dealt to two players owes nothing. The
+// blinds passing the seats of players sitting out are recorded as missed.
+// The caller must hold the mutex.
+func (g *Game) seatReturningNoLock() {
+	dealer, sb, bb := g.CurrentDealer, g.SmallBlindSeat, g.BigBlindSeat
+	g.moveButtonNoLock()
+	if len(g.inHand) > 2 {
+		players := make([]uuid.UUID, 0, len(g.inHand))
+		for _, pid := range g.inHand {
+			if s, ok := g.sitting[pid]; ok && !s.post && g.Seats[pid] != g.BigBlindSeat {
+				continue
+			}
+			players = append(players, pid)
+		}
+		if len(players) < len(g.inHand) {
+			g.inHand = players
+			g.CurrentDealer, g.SmallBlindSeat, g.BigBlindSeat = dealer, sb, bb
+			g.moveButtonNoLock()
+		}
+	}
+	for _, pid := range g.inHand {
+		if s, ok := g.sitting[pid]; ok && (len(g.inHand) <= 2 || g.Seats[pid] == g.BigBlindSeat) {
+			// the big blind makes up for the blinds missed
+			s.missed = 0
+			g.sitting[pid] = s
+		}
+	}
+	if bb == 0 {
+		return
+	}
+	for pid, s := range g.sitting {
+		seat, ok := g.Seats[pid]
+		if !ok || g.isInHandNoLock(pid) {
+			continue
+		}
+		if seatBetween(sb, seat, g.SmallBlindSeat) {
+			s.missed |= MissedSmallBlind
+		}
+		if seatBetween(bb, seat, g.BigBlindSeat) {
+			s.missed |= MissedBigBlind
+		}
+		g.sitting[pid] = s
+	}
+}
+
+// postMissedNoLock posts the missed blinds of the players who sat back in
+// and are dealt into the hand. The caller must hold the mutex.
+func (g *Game) postMissedNoLock() {
+	for _, pid := range g.inHand {
+		s, ok := g.sitting[pid]
+		if !ok {
+			continue
+		}
+		if s.missed&MissedBigBlind != 0 {
+			g.postNoLock(pid, ActionBigBlind, g.BigBlind, true)
+		}
+		if s.missed&MissedSmallBlind != 0 {
+			g.postNoLock(pid, ActionDeadBlind, g.SmallBlind, false)
+		}
+		delete(g.sitting, pid)
+	}
+}
+
+// seatBetween reports whether seat lies strictly between the seats from and
+// to going around the table.
+func seatBetween(from, seat, to int) bool {
+	switch {
+	case from == to:
+		return false
+	case from < to:
+		return from < seat && seat < to
+	}
+	return seat > from || seat < to
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// sitOutTwoHands seats four players, sits the last one out and plays two
+// more hands so the big and then the small blind pass the empty seat.
+func sitOutTwoHands(t *testing.T) (*Game, []uuid.UUID) {
+	t.Helper()
+	g, players := newStartedGame(t, withSeats(1, 2, 3, 4), 1000, 1000, 1000, 1000)
+	if err := g.SitOut(players[3]); err != nil {
+		t.Fatalf("sit out: %v", err)
+	}
+	if err := g.SitOut(players[3]); err == nil {
+		t.Fatal("expected error sitting out twice")
+	}
+	// the player sits out from the next hand on
+	if !g.isInHandNoLock(players[3]) {
+		t.Fatal("expected the player to finish the current hand")
+	}
+	nextRound(t, g)
+	if g.isInHandNoLock(players[3]) || g.BigBlindSeat != 1 {
+		t.Fatalf("expected the big blind to skip seat 4 got seat %d", g.BigBlindSeat)
+	}
+	if g.MissedBlinds(players[3]) != MissedBigBlind {
+		t.Fatalf("expected a missed big blind got %d", g.MissedBlinds(players[3]))
+	}
+	nextRound(t, g)
+	if g.MissedBlinds(players[3]) != MissedSmallBlind|MissedBigBlind {
+		t.Fatalf("expected both blinds missed got %d", g.MissedBlinds(players[3]))
+	}
+	return g, players
+}
+
+// handEntries returns the player entries of the current hand.
+func handEntries(t *testing.T, g *Game) []Action {
+	t.Helper()
+	var entries []Action
+	for _, entry := range g.ActionLog {
+		a, err := ParseAction(entry)
+		if err != nil {
+			t.Fatalf("parse %s: %v", entry, err)
+		}
+		if a.Kind == EntryStreet && a.Street.Name == StreetPreflop.String() {
+			entries = nil
+		}
+		if a.Kind == EntryPlayer {
+			entries = append(entries, a)
+		}
+	}
+	return entries
+}
+
+func TestSitInPostingMissedBlinds(t *testing.T) {
+	g, players := sitOutTwoHands(t)
+	if err := g.SitIn(players[3], true); err != nil {
+		t.Fatalf("sit in: %v", err)
+	}
+	if g.SittingOut(players[3]) {
+		t.Fatal("expected the player to be back")
+	}
+	nextRound(t, g)
+	if !g.isInHandNoLock(players[3]) || g.BigBlindSeat != 3 {
+		t.Fatalf("expected the player dealt in with the big blind on seat 3 got %d", g.BigBlindSeat)
+	}
+	id := players[3].String()
+	var big, dead bool
+	for _, a := range handEntries(t, g) {
+		big = big || (a.Player == id && a.Code == ActionBigBlind && a.Amount == 100)
+		dead = dead || (a.Player == id && a.Code == ActionDeadBlind && a.Amount == 50)
+	}
+	if !big || !dead {
+		t.Fatalf("expected a live big blind and a dead small blind in %v", g.ActionLog)
+	}
+	if g.PotTotal() != 300 || g.MissedBlinds(players[3]) != 0 {
+		t.Fatalf("unexpected pot %d or missed blinds %d", g.PotTotal(), g.MissedBlinds(players[3]))
+	}
+	if _, err := Replay(g.ActionLog, g.CardSequence); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+}
+
+func TestSitInWaitingForBigBlind(t *testing.T) {
+	g, players := sitOutTwoHands(t)
+	if err := g.SitIn(players[3], false); err != nil {
+		t.Fatalf("sit in: %v", err)
+	}
+	nextRound(t, g)
+	if g.isInHandNoLock(players[3]) || !g.SittingOut(players[3]) {
+		t.Fatal("expected the player to wait for the big blind")
+	}
+	nextRound(t, g)
+	if !g.isInHandNoLock(players[3]) || g.BigBlindSeat != 4 {
+		t.Fatalf("expected the player dealt in as the big blind got seat %d", g.BigBlindSeat)
+	}
+	for _, a := range handEntries(t, g) {
+		if a.Code == ActionDeadBlind {
+			t.Fatalf("unexpected missed blind %+v", a)
+		}
+	}
+	if g.SittingOut(players[3]) || g.MissedBlinds(players[3]) != 0 {
+		t.Fatal("expected the missed blinds to be made up")
+	}
+}
+
+func TestSitOutCarriedToNextGame(t *testing.T) {
+	g, players := sitOutTwoHands(t)
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	table := &Table{ID: g.TableID}
+	next, err := table.NextGame(g)
+	if err != nil {
+		t.Fatalf("next game: %v", err)
+	}
+	if !next.SittingOut(players[3]) || next.MissedBlinds(players[3]) != MissedSmallBlind|MissedBigBlind {
+		t.Fatal("expected the player to keep sitting out")
+	}
+	if err := next.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if next.isInHandNoLock(players[3]) {
+		t.Fatal("expected the player left out of the next game")
+	}
+	if _, err := Replay(next.ActionLog, next.CardSequence); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"pokerDB/pkg/rules/betting"
+)
+
+// Street identifies the betting round of the hand currently being played.
+type Street int
+
+const (
+	StreetNone     Street = iota // no hand in progress
+	StreetPreflop                // hole cards and first betting round
+	StreetFlop                   // three community cards dealt
+	StreetTurn                   // fourth community card dealt
+	StreetRiver                  // fifth community card dealt
+	StreetShowdown               // betting finished, hand awaits resolution
+)
+
+var streetNames = map[Street]string{
+	StreetNone:     "none",
+	StreetPreflop:  "preflop",
+	StreetFlop:     "flop",
+	StreetTurn:     "turn",
+	StreetRiver:    "river",
+	StreetShowdown: "showdown",
+}
+
+// nextStreets maps each betting street to the street dealt after it.
+var nextStreets = map[Street]Street{
+	StreetPreflop: StreetFlop,
+	StreetFlop:    StreetTurn,
+	StreetTurn:    StreetRiver,
+}
+
+// boardCards holds the number of community cards dealt for each street.
+var boardCards = map[Street]int{
+	StreetFlop:  3,
+	StreetTurn:  1,
+	StreetRiver: 1,
+}
+
+// streetsAfter returns the streets still to be dealt once the board holds
+// n community cards.
+func streetsAfter(n int) []Street {
+	streets := []Street{}
+	for _, street := range []Street{StreetFlop, StreetTurn, StreetRiver} {
+		if n > 0 {
+			n -= boardCards[street]
+			continue
+		}
+		streets = append(streets, street)
+	}
+	return streets
+}
+
+func (s Street) String() string {
+	if n, ok := streetNames[s]; ok {
+		return n
+	}
+	return strconv.Itoa(int(s))
+}
+
+// BettingClosed reports whether betting on the current street is complete.
+func (g *Game) BettingClosed() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.bettingClosed
+}
+
+// HoleCards returns the hole cards dealt to a player in the current hand.
+func (g *Game) HoleCards(playerID uuid.UUID) []int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	cards := g.holeCards[playerID]
+	out := make([]int, len(cards))
+	copy(out, cards)
+	return out
+}
+
+// NextStreet deals the community cards for the next street once betting on
+// the current street is complete and returns the newly dealt cards.
+func (g *Game) NextStreet() ([]int, error) {
+	g.mu.Lock()
+	defer g.unlock()
+	return g.nextStreetNoLock()
+}
+
+func (g *Game) nextStreetNoLock() ([]int, error) {
+	if !g.inRound {
+		return nil, errors.New("no active round")
+	}
+	if !g.holeDealt {
+		return nil, errors.New("hole cards not dealt")
+	}
+	if !g.bettingClosed {
+		return nil, fmt.Errorf("betting on %s not complete", g.CurrentStreet)
+	}
+	next, ok := nextStreets[g.CurrentStreet]
+	if !ok {
+		return nil, fmt.Errorf("no street follows %s", g.CurrentStreet)
+	}
+	cards, err := g.dealBoardNoLock(boardCards[next])
+	if err != nil {
+		return nil, err
+	}
+	g.Board = append(g.Board, cards...)
+	g.beginStreetNoLock(next, cards)
+	return cards, nil
+}
+
+// seatOrderNoLock returns the players holding chips ordered by seat number.
+// The caller must hold the mutex.
+func (g *Game) seatOrderNoLock() []uuid.UUID {
+	players := make([]uuid.UUID, 0, len(g.Stacks))
+	for pid := range g.Stacks {
+		if g.Seats[pid] > 0 {
+			players = append(players, pid)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool { return g.Seats[players[i]] < g.Seats[players[j]] })
+	return players
+}
+
+// assignSeatsNoLock gives every player holding chips without a seat the lowest
+// free seat, in buy-in order. The caller must hold the mutex.
+func (g *Game) assignSeatsNoLock() {
+	if g.Seats == nil {
+		g.Seats = make(map[uuid.UUID]int)
+	}
+	taken := make(map[int]bool)
+	for _, s := range g.Seats {
+		taken[s] = true
+	}
+	next := 1
+	for _, b := range g.BuyIns {
+		if _, ok := g.Stacks[b.PlayerID]; !ok || g.Seats[b.PlayerID] > 0 {
+			continue
+		}
+		for taken[next] && next <= MaxSeats {
+			next++
+		}
+		if next > MaxSeats {
+			logrus.Warn("no free seat for player ", b.PlayerID)
+			return
+		}
+		g.Seats[b.PlayerID] = next
+		taken[next] = true
+	}
+}
+
+// dealtInNoLock returns the players with chips who will be dealt into the
+// next hand, leaving out those sitting out. The caller must hold the mutex.
+func (g *Game) dealtInNoLock() []uuid.UUID {
+	g.assignSeatsNoLock()
+	players := []uuid.UUID{}
+	for _, pid := range g.seatOrderNoLock() {
+		if g.Stacks[pid] > 0 && !g.sitting[pid].out {
+			players = append(players, pid)
+		}
+	}
+	return players
+}
+
+// startHandNoLock resets the per-hand state and opens the preflop street.
+// The caller must hold the mutex.
+func (g *Game) startHandNoLock() {
+	// a hand that was never awarded is void
+	g.refundHandNoLock()
+	g.inHand = g.dealtInNoLock()
+	g.holeCards = make(map[uuid.UUID][]int)
+	g.folded = make(map[uuid.UUID]bool)
+	g.holeDealt = false
+	g.Board = IntSlice{}
+	g.Boards = nil
+	g.runs = make(map[uuid.UUID]int)
+	g.seatReturningNoLock()
+	g.beginStreetNoLock(StreetPreflop, nil)
+	g.postForcedBetsNoLock()
+	g.updateBettingNoLock()
+	g.openActionNoLock()
+}
+
+// clearHandNoLock discards the per-hand state once a hand is over.
+// The caller must hold the mutex.
+func (g *Game) clearHandNoLock() {
+	g.CurrentStreet = StreetNone
+	g.inHand = nil
+	g.holeDealt = false
+	g.bettingClosed = false
+	g.betRound = betting.Round{}
+	g.currentBets = make(map[uuid.UUID]int64)
+	g.actionOn = -1
+}
+
+// beginStreetNoLock opens betting on a new street and records the cards
+// dealt for it. The caller must hold the mutex.
+func (g *Game) beginStreetNoLock(street Street, cards []int) {
+	g.CurrentStreet = street
+	g.currentBets = make(map[uuid.UUID]int64)
+	g.acted = make(map[uuid.UUID]bool)
+	// fixed-limit games bet the big bet from the turn on
+	g.betRound = g.Betting.NewRound(g.BigBlind, street == StreetTurn || street == StreetRiver)
+	g.bettingClosed = false
+	g.logNoLock(Action{Kind: EntryStreet, Street: &StreetDeal{Name: street.String(), Cards: cards}, Time: g.nowNoLock()})
+	g.updateBettingNoLock()
+	g.openActionNoLock()
+}
+
+// isInHandNoLock reports whether a player was dealt into the current hand.
+// The caller must hold the mutex.
+func (g *Game) isInHandNoLock(playerID uuid.UUID) bool {
+	for _, pid := range g.inHand {
+		if pid == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// livePlayersNoLock returns the players dealt in who have not folded, in
+// seat order. The caller must hold the mutex.
+func (g *Game) livePlayersNoLock() []uuid.UUID {
+	live := []uuid.UUID{}
+	for _, pid := range g.inHand {
+		if !g.folded[pid] {
+			live = append(live, pid)
+		}
+	}
+	return live
+}
+
+// updateBettingNoLock closes the current street once no further action is
+// required and moves to showdown when the hand cannot continue. The caller
+// must hold the mutex.
+func (g *Game) updateBettingNoLock() {
+	live := g.livePlayersNoLock()
+	if len(live) <= 1 {
+		g.closeBettingNoLock()
+		g.CurrentStreet = StreetShowdown
+		return
+	}
+	canAct := 0
+	pending := 0
+	// the highest bet actually made, which is below the current bet when
+	// the big blind is posted short
+	var highest int64
+	for _, pid := range live {
+		if g.currentBets[pid] > highest {
+			highest = g.currentBets[pid]
+		}
+		if g.Stacks[pid] == 0 {
+			continue
+		}
+		canAct++
+		if !g.acted[pid] || g.currentBets[pid] < g.betRound.CurrentBet {
+			pending++
+		}
+	}
+	closed := pending == 0
+	switch canAct {
+	case 0:
+		closed = true
+	case 1:
+		// nobody is left to bet against, so only a pending call matters
+		closed = true
+		for _, pid := range live {
+			if g.Stacks[pid] > 0 && g.currentBets[pid] < highest {
+				closed = false
+			}
+		}
+	}
+	if !closed {
+		return
+	}
+	g.closeBettingNoLock()
+	if g.CurrentStreet == StreetRiver {
+		g.CurrentStreet = StreetShowdown
+	}
+}
+
+// closeBettingNoLock marks the street as complete and returns any uncalled
+// bet the first time it is closed. The caller must hold the mutex.
+func (g *Game) closeBettingNoLock() {
+	if g.bettingClosed {
+		return
+	}
+	g.bettingClosed = true
+	g.returnUncalledNoLock()
+}
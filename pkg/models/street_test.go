@@ -0,0 +1,182 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// gameOption configures a game for newStartedGame before the players buy
+// in.
+type gameOption func(g *Game, players []uuid.UUID)
+
+// withSeats seats the players in the given seats.
+func withSeats(seats ...int) gameOption {
+	return func(g *Game, players []uuid.UUID) {
+		for i, seat := range seats {
+			g.Seats[players[i]] = seat
+		}
+	}
+}
+
+// withShuffler shuffles the game's decks with s.
+func withShuffler(s Shuffler) gameOption {
+	return func(g *Game, _ []uuid.UUID) {
+		g.Shuffler = s
+	}
+}
+
+// newStartedGame buys in one player per stack to a game with 50/100
+// blinds, configured by the option when it is not nil, and starts it.
+func newStartedGame(t *testing.T, option gameOption, stacks ...int64) (*Game, []uuid.UUID) {
+	t.Helper()
+	g := NewGame(uuid.New(), len(stacks))
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.MinBuyIn = 100
+	g.MaxBuyIn = 1000
+	players := make([]uuid.UUID, len(stacks))
+	for i := range stacks {
+		players[i] = uuid.New()
+	}
+	if option != nil {
+		option(g, players)
+	}
+	for i, s := range stacks {
+		if err := g.BuyIn(players[i], s); err != nil {
+			t.Fatalf("buyin %d: %v", i, err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	return g, players
+}
+
+// checkOrCall checks or calls for the player to act.
+func checkOrCall(t *testing.T, g *Game) {
+	t.Helper()
+	for _, a := range g.LegalActions() {
+		if a.Code == ActionCheck {
+			if err := g.AddAction(g.ActionOn(), ActionCheck, a.Max); err != nil {
+				t.Fatalf("%s check: %v", g.CurrentStreet, err)
+			}
+			return
+		}
+	}
+	t.Fatalf("player cannot check or call on %s", g.CurrentStreet)
+}
+
+func TestStreetTransitions(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500)
+	p1, p2 := players[0], players[1]
+	if g.CurrentStreet != StreetPreflop {
+		t.Fatalf("expected preflop got %s", g.CurrentStreet)
+	}
+	if err := g.AddAction(p1, ActionCheck, 0); err == nil {
+		t.Fatal("expected error before hole cards are dealt")
+	}
+	if hands := g.DealHands(); len(hands) != 2 || len(hands[0]) != 2 {
+		t.Fatalf("unexpected hands %v", hands)
+	}
+	if hands := g.DealHands(); len(hands) != 0 {
+		t.Fatal("hole cards should only be dealt once")
+	}
+	if cards := g.Deal(3); len(cards) != 0 {
+		t.Fatal("flop dealt before betting completed")
+	}
+
+	for _, street := range []Street{StreetFlop, StreetTurn, StreetRiver} {
+		for !g.BettingClosed() {
+			checkOrCall(t, g)
+		}
+		if !g.BettingClosed() {
+			t.Fatalf("betting should be closed on %s", g.CurrentStreet)
+		}
+		if cards := g.Deal(boardCards[street] + 1); len(cards) != 0 {
+			t.Fatalf("dealt wrong card count on %s", street)
+		}
+		cards, err := g.NextStreet()
+		if err != nil {
+			t.Fatalf("next street: %v", err)
+		}
+		if len(cards) != boardCards[street] || g.CurrentStreet != street {
+			t.Fatalf("expected %s got %s with %v", street, g.CurrentStreet, cards)
+		}
+	}
+	if len(g.Board) != 5 {
+		t.Fatalf("expected 5 board cards got %d", len(g.Board))
+	}
+	if err := g.AddAction(p2, ActionRaise, 100); err != nil {
+		t.Fatalf("river bet: %v", err)
+	}
+	if err := g.AddAction(p1, ActionCheck, 100); err != nil {
+		t.Fatalf("river call: %v", err)
+	}
+	if g.CurrentStreet != StreetShowdown {
+		t.Fatalf("expected showdown got %s", g.CurrentStreet)
+	}
+	if _, err := g.NextStreet(); err == nil {
+		t.Fatal("expected error dealing after river")
+	}
+	if err := g.AddAction(p1, ActionCheck, 0); err == nil {
+		t.Fatal("expected error acting at showdown")
+	}
+}
+
+func TestStreetFoldEndsHand(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500, 500)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionRaise, 200); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionFold, 0); err != nil {
+		t.Fatalf("fold p2: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 0); err == nil {
+		t.Fatal("folded player should not act")
+	}
+	if err := g.AddAction(players[2], ActionFold, 0); err != nil {
+		t.Fatalf("fold p3: %v", err)
+	}
+	if g.CurrentStreet != StreetShowdown {
+		t.Fatalf("expected showdown got %s", g.CurrentStreet)
+	}
+	if err := g.EndRound(); err != nil {
+		t.Fatalf("end round: %v", err)
+	}
+	if g.CurrentStreet != StreetNone {
+		t.Fatalf("expected no street got %s", g.CurrentStreet)
+	}
+	if err := g.StartRound(); err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	if g.CurrentStreet != StreetPreflop || len(g.Board) != 0 {
+		t.Fatalf("expected fresh preflop got %s %v", g.CurrentStreet, g.Board)
+	}
+}
+
+func TestStreetAllInRunout(t *testing.T) {
+	g, players := newStartedGame(t, nil, 300, 500)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionAllIn, 300); err != nil {
+		t.Fatalf("all-in: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 300); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	for _, street := range []Street{StreetFlop, StreetTurn} {
+		if _, err := g.NextStreet(); err != nil {
+			t.Fatalf("runout %s: %v", street, err)
+		}
+		if !g.BettingClosed() {
+			t.Fatalf("no betting expected on %s", street)
+		}
+	}
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("runout river: %v", err)
+	}
+	if g.CurrentStreet != StreetShowdown {
+		t.Fatalf("expected showdown got %s", g.CurrentStreet)
+	}
+}
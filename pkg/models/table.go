@@ -1,8 +1,11 @@
 package models
 
 import (
-	"github.com/google/uuid"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Table struct {
@@ -11,3 +14,98 @@ type Table struct {
 	StartedTime time.Time `json:"started_time" gorm:"type:timestamp"`
 	EndedTime   time.Time `json:"ended_time" gorm:"type:timestamp"`
 }
+
+// NextGame creates the game that follows prev at the table. Players still
+// holding chips are carried over with their stacks as carried buy-ins and
+// keep their seats unless they picked a new one with ChooseSeat; both are
+// logged before the game starts, as are the players sitting out with their
+// missed blinds. Options, the shuffler, the clock and the button position
+// are copied so the button keeps moving by seat. Time banks start full
+// again.
+// The returned game still has to be started.
+func (t *Table) NextGame(prev *Game) (*Game, error) {
+	if prev == nil {
+		return nil, errors.New("no previous game")
+	}
+	prev.mu.RLock()
+	defer prev.mu.RUnlock()
+	if prev.EndedTime.IsZero() {
+		return nil, errors.New("previous game not ended")
+	}
+	if prev.TableID != t.ID {
+		return nil, fmt.Errorf("game %s not played at table %s", prev.ID, t.ID)
+	}
+
+	g := NewGame(t.ID, 0)
+	g.Ante = prev.Ante
+	g.SmallBlind = prev.SmallBlind
+	g.BigBlind = prev.BigBlind
+	g.AllowRunItTwice = prev.AllowRunItTwice
+	g.AllowStraddle = prev.AllowStraddle
+	g.Betting = prev.Betting
+	g.Variant = prev.Variant
+	g.Rake = prev.Rake
+	g.DecisionTime = prev.DecisionTime
+	g.TimeBank = prev.TimeBank
+	g.MinBuyIn = prev.MinBuyIn
+	g.MaxBuyIn = prev.MaxBuyIn
+	g.MaxRebuys = prev.MaxRebuys
+	g.Shuffler = prev.Shuffler
+	g.Clock = prev.Clock
+	if _, ok := prev.Shuffler.(*FairShuffler); ok {
+		// the server seed of the previous game has been revealed
+		f, err := NewFairShuffler()
+		if err != nil {
+			return nil, err
+		}
+		g.Shuffler = f
+	}
+	g.CurrentDealer = prev.CurrentDealer
+	g.SmallBlindSeat = prev.SmallBlindSeat
+	g.BigBlindSeat = prev.BigBlindSeat
+
+	players := prev.seatOrderNoLock()
+	taken := make(map[int]bool)
+	for _, pid := range players {
+		if seat, ok := prev.NextSeats[pid]; ok && prev.Stacks[pid] > 0 {
+			g.Seats[pid] = seat
+			taken[seat] = true
+		}
+	}
+	for _, pid := range players {
+		stack := prev.Stacks[pid]
+		if stack <= 0 {
+			continue
+		}
+		if _, ok := g.Seats[pid]; !ok {
+			// players whose seat was taken are seated when the game starts
+			g.Seats[pid] = -1
+			if seat := prev.Seats[pid]; !taken[seat] {
+				g.Seats[pid] = seat
+				taken[seat] = true
+			}
+		}
+		g.BuyIns = append(g.BuyIns, BuyIn{PlayerID: pid, Amount: stack, Carried: true})
+		g.Stacks[pid] = stack
+		g.logActionNoLock(pid, ActionBuyIn, stack)
+		if seat := g.Seats[pid]; seat > 0 {
+			// seat entries before the start entry record the seat taken in
+			// this game
+			g.logActionNoLock(pid, ActionSeat, int64(seat))
+		}
+		if s, ok := prev.sitting[pid]; ok {
+			// players sitting out stay out and still owe their missed
+			// blinds
+			if err := g.sitOutCarriedNoLock(pid, s.missed); err != nil {
+				return nil, err
+			}
+			if !s.out {
+				if err := g.SitIn(pid, s.post); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	g.PersonCount = len(g.BuyIns)
+	return g, nil
+}
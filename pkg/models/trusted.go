@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNoReason is returned when the deck is requested without stating why.
+var ErrNoReason = errors.New("deck access requires a reason")
+
+// trustedGame is the JSON encoding of a game including its deck.
+type trustedGame struct {
+	*Game
+	CardSequence IntSlice `json:"card_sequence"`
+}
+
+// RevealDeck returns a copy of the shuffled deck. The deck holds every card
+// still to come, so it is neither logged nor part of the JSON encoding of a
+// game; RevealDeck is meant for trusted code such as an audit after the game
+// and records every call together with its reason.
+func (g *Game) RevealDeck(reason string) (IntSlice, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if err := g.auditDeckNoLock(reason); err != nil {
+		return nil, err
+	}
+	return append(IntSlice{}, g.CardSequence...), nil
+}
+
+// MarshalTrusted returns the JSON encoding of the game with its deck under
+// "card_sequence" for trusted persistence, such as a backup outside the
+// database. The access is recorded like RevealDeck.
+func (g *Game) MarshalTrusted(reason string) ([]byte, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if err := g.auditDeckNoLock(reason); err != nil {
+		return nil, err
+	}
+	return json.Marshal(trustedGame{Game: g, CardSequence: g.CardSequence})
+}
+
+// DealtCards returns the record of every card dealt from the deck in this
+// game, burn cards and hole cards included, in the order they were dealt.
+// Like RevealDeck it is meant for trusted code and records the access.
+func (g *Game) DealtCards(reason string) ([]DealtCard, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if err := g.auditDeckNoLock(reason); err != nil {
+		return nil, err
+	}
+	if g.deck == nil {
+		return []DealtCard{}, nil
+	}
+	return g.deck.Dealt(), nil
+}
+
+// UnmarshalTrusted decodes a game written by MarshalTrusted, deck included.
+// Like a game loaded from the database, its hands have to be restored with
+// ReplayGame.
+func UnmarshalTrusted(data []byte) (*Game, error) {
+	t := trustedGame{Game: NewGame(uuid.Nil, 0)}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	t.Game.CardSequence = t.CardSequence
+	return t.Game, nil
+}
+
+// auditDeckNoLock records an access to the deck without its cards. The
+// caller must hold the mutex.
+func (g *Game) auditDeckNoLock(reason string) error {
+	if reason == "" {
+		logrus.Warn("Deck access without a reason")
+		return ErrNoReason
+	}
+	logrus.Infof("Deck of game %s accessed: %s", g.ID, reason)
+	return nil
+}
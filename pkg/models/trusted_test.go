@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeckKeptOutOfJSON(t *testing.T) {
+	g, _ := newStartedGame(t, nil, 1000, 1000)
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), "card_sequence") {
+		t.Fatalf("expected no deck in %s", data)
+	}
+	if _, err := g.RevealDeck(""); !errors.Is(err, ErrNoReason) {
+		t.Fatalf("expected ErrNoReason got %v", err)
+	}
+	deck, err := g.RevealDeck("audit")
+	if err != nil || !reflect.DeepEqual(deck, g.CardSequence) {
+		t.Fatalf("unexpected deck %v: %v", deck, err)
+	}
+	deck[0] = 0
+	if g.CardSequence[0] == 0 {
+		t.Fatal("expected a copy of the deck")
+	}
+}
+
+func TestMarshalTrusted(t *testing.T) {
+	g, _ := newStartedGame(t, nil, 1000, 1000)
+	if _, err := g.MarshalTrusted(""); !errors.Is(err, ErrNoReason) {
+		t.Fatalf("expected ErrNoReason got %v", err)
+	}
+	data, err := g.MarshalTrusted("backup")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	loaded, err := UnmarshalTrusted(data)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if loaded.ID != g.ID || !reflect.DeepEqual(loaded.CardSequence, g.CardSequence) || len(loaded.ActionLog) != len(g.ActionLog) {
+		t.Fatalf("unexpected game %+v", loaded)
+	}
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"pokerDB/pkg/rules/betting"
+)
+
+// TurnError is returned by AddAction when a player acts while the action is
+// on somebody else.
+type TurnError struct {
+	PlayerID uuid.UUID // player who tried to act
+	ActionOn uuid.UUID // player whose turn it is
+}
+
+func (e *TurnError) Error() string {
+	return fmt.Sprintf("player %s acted out of turn; action is on %s", e.PlayerID, e.ActionOn)
+}
+
+// LegalAction describes an action available to the player whose turn it is.
+// Min and Max bound the amount passed to AddAction, which is the player's
+// total bet on the current street.
+type LegalAction struct {
+	Code string `json:"code"`
+	Min  int64  `json:"min"`
+	Max  int64  `json:"max"`
+}
+
+// ActionOn returns the player whose turn it is to act. uuid.Nil is returned
+// when no action is pending.
+func (g *Game) ActionOn() uuid.UUID {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.actionOnNoLock()
+}
+
+// LegalActions returns the actions the player to act may take together with
+// the allowed amounts. It returns nil when no action is pending. Raises are
+// bounded by the game's betting structure: in pot-limit games by the pot
+// after calling and in fixed-limit games to exactly one bet, until the
+// raise cap is reached.
+func (g *Game) LegalActions() []LegalAction {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.legalActionsNoLock()
+}
+
+func (g *Game) actionOnNoLock() uuid.UUID {
+	if !g.holeDealt || g.actionOn < 0 || g.actionOn >= len(g.inHand) {
+		return uuid.Nil
+	}
+	return g.inHand[g.actionOn]
+}
+
+func (g *Game) legalActionsNoLock() []LegalAction {
+	if !g.inRound || !g.holeDealt || g.bettingClosed {
+		return nil
+	}
+	pid := g.actionOnNoLock()
+	if pid == uuid.Nil {
+		return nil
+	}
+	stack := g.Stacks[pid]
+	bet := g.currentBets[pid]
+	toCall := g.betRound.CurrentBet - bet
+	actions := []LegalAction{{Code: ActionFold}}
+	if toCall <= 0 {
+		actions = append(actions, LegalAction{Code: ActionCheck})
+	} else if stack > toCall {
+		actions = append(actions, LegalAction{Code: ActionCheck, Min: g.betRound.CurrentBet, Max: g.betRound.CurrentBet})
+	}
+	// a player who already acted may only raise again after a full raise
+	canRaise := !g.acted[pid] && g.opponentCanActNoLock(pid)
+	limit := g.betRound.MaxRaiseTo(g.potTotalNoLock(), bet)
+	if stack > toCall && canRaise {
+		if min := g.betRound.MinRaiseTo(); min <= bet+stack && min <= limit {
+			max := bet + stack
+			if limit < max {
+				max = limit
+			}
+			actions = append(actions, LegalAction{Code: ActionRaise, Min: min, Max: max})
+		}
+	}
+	if bet+stack > g.betRound.CurrentBet && (g.acted[pid] || bet+stack > limit) {
+		// going all-in would be a raise the player is not allowed to make
+		return actions
+	}
+	actions = append(actions, LegalAction{Code: ActionAllIn, Min: bet + stack, Max: bet + stack})
+	return actions
+}
+
+// checkLegalNoLock verifies that the action fits the turn order and the
+// legal actions of the player to act. Raises must be full raises within the
+// limit of the betting structure as defined by the betting rules. The caller
+// must hold the mutex.
+func (g *Game) checkLegalNoLock(playerID uuid.UUID, code string, amount int64) error {
+	if on := g.actionOnNoLock(); on != playerID {
+		return &TurnError{PlayerID: playerID, ActionOn: on}
+	}
+	for _, a := range g.legalActionsNoLock() {
+		if a.Code != code {
+			continue
+		}
+		if code == ActionRaise {
+			if err := g.betRound.CheckRaise(amount); err != nil {
+				return fmt.Errorf("%w: minimum raise is to %d", err, a.Min)
+			}
+			if err := g.betRound.CheckLimit(amount, g.potTotalNoLock(), g.currentBets[playerID]); err != nil {
+				return fmt.Errorf("%w: maximum raise is to %d", err, a.Max)
+			}
+			if amount > a.Max {
+				return fmt.Errorf("insufficient chips")
+			}
+			return nil
+		}
+		if amount < a.Min || amount > a.Max {
+			return fmt.Errorf("%s amount must be %d", ActionToWord(code), a.Min)
+		}
+		return nil
+	}
+	if code == ActionRaise && g.acted[playerID] {
+		return betting.ErrNotReopened
+	}
+	if code == ActionRaise || code == ActionAllIn {
+		if err := g.betRound.CheckLimit(amount, g.potTotalNoLock(), g.currentBets[playerID]); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("action %s not allowed for player %s", code, playerID)
+}
+
+// opponentCanActNoLock reports whether another live player still has chips
+// to respond to a raise. The caller must hold the mutex.
+func (g *Game) opponentCanActNoLock(playerID uuid.UUID) bool {
+	for _, pid := range g.livePlayersNoLock() {
+		if pid != playerID && g.Stacks[pid] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// buttonNoLock returns the index of the dealer button within the players
+// dealt into the current hand. The caller must hold the mutex.
+func (g *Game) buttonNoLock() int {
+	if g.button >= len(g.inHand) {
+		return 0
+	}
+	return g.button
+}
+
+// needsActionNoLock reports whether the player at index i still has to act
+// on the current street. The caller must hold the mutex.
+func (g *Game) needsActionNoLock(i int) bool {
+	pid := g.inHand[i]
+	if g.folded[pid] || g.Stacks[pid] == 0 {
+		return false
+	}
+	return !g.acted[pid] || g.currentBets[pid] < g.betRound.CurrentBet
+}
+
+// advanceActionNoLock moves the action to the next player after index from
+// who still has to act. The caller must hold the mutex.
+func (g *Game) advanceActionNoLock(from int) {
+	g.actionOn = -1
+	if g.bettingClosed {
+		return
+	}
+	n := len(g.inHand)
+	for k := 1; k <= n; k++ {
+		i := ((from+k)%n + n) % n
+		if g.needsActionNoLock(i) {
+			g.actionOn = i
+			g.startTurnNoLock()
+			return
+		}
+	}
+}
+
+// openActionNoLock puts the action on the first player of the street:
+// left of the big blind or straddle preflop (the button heads-up) and left
+// of the button afterwards. The caller must hold the mutex.
+func (g *Game) openActionNoLock() {
+	if g.CurrentStreet == StreetPreflop {
+		g.advanceActionNoLock(g.lastBlind)
+		return
+	}
+	g.advanceActionNoLock(g.buttonNoLock())
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"pokerDB/pkg/rules/betting"
+)
+
+func TestActionOrder(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500, 500, 500)
+	if g.ActionOn() != uuid.Nil {
+		t.Fatal("no action expected before hole cards are dealt")
+	}
+	g.DealHands()
+	// four players with the button on seat 1: action starts left of the big blind
+	if g.ActionOn() != players[3] {
+		t.Fatalf("expected player 4 to act first preflop")
+	}
+	err := g.AddAction(players[0], ActionCheck, 0)
+	var turnErr *TurnError
+	if !errors.As(err, &turnErr) || turnErr.ActionOn != players[3] {
+		t.Fatalf("expected turn error got %v", err)
+	}
+	if err := g.AddAction(players[3], ActionRaise, 200); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	for _, p := range players[:3] {
+		if g.ActionOn() != p {
+			t.Fatalf("expected action on %s got %s", p, g.ActionOn())
+		}
+		if err := g.AddAction(p, ActionCheck, 200); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	if g.ActionOn() != players[1] {
+		t.Fatal("expected first player left of the button to act on the flop")
+	}
+}
+
+func TestActionOrderHeadsUp(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 500)
+	g.DealHands()
+	if g.ActionOn() != players[0] {
+		t.Fatal("button should act first preflop heads-up")
+	}
+	if err := g.AddAction(players[0], ActionCheck, 100); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 0); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	if g.ActionOn() != players[1] {
+		t.Fatal("big blind should act first after the flop heads-up")
+	}
+}
+
+func TestLegalActions(t *testing.T) {
+	g, players := newStartedGame(t, nil, 500, 300)
+	if g.LegalActions() != nil {
+		t.Fatal("no legal actions expected before dealing")
+	}
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionRaise, 400); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	legal := g.LegalActions()
+	codes := map[string]LegalAction{}
+	for _, a := range legal {
+		codes[a.Code] = a
+	}
+	if _, ok := codes[ActionCheck]; ok {
+		t.Fatal("short stack cannot check or call 400")
+	}
+	if _, ok := codes[ActionRaise]; ok {
+		t.Fatal("short stack cannot raise")
+	}
+	if a, ok := codes[ActionAllIn]; !ok || a.Min != 300 || a.Max != 300 {
+		t.Fatalf("expected all-in for 300 got %+v", a)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 400); err == nil {
+		t.Fatal("expected call for more than the stack to fail")
+	}
+	if err := g.AddAction(players[1], ActionAllIn, 300); err != nil {
+		t.Fatalf("all-in: %v", err)
+	}
+	if !g.BettingClosed() || g.LegalActions() != nil {
+		t.Fatal("betting should be closed")
+	}
+}
+
+func TestMinRaise(t *testing.T) {
+	g, players := newStartedGame(t, nil, 1000, 1000, 1000)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionRaise, 150); !errors.Is(err, betting.ErrRaiseTooSmall) {
+		t.Fatalf("expected raise too small got %v", err)
+	}
+	if err := g.AddAction(players[0], ActionRaise, 300); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	// the last raise was 200 so the next one has to reach 500
+	for _, a := range g.LegalActions() {
+		if a.Code == ActionRaise && a.Min != 500 {
+			t.Fatalf("expected minimum raise to 500 got %d", a.Min)
+		}
+	}
+	if err := g.AddAction(players[1], ActionRaise, 450); !errors.Is(err, betting.ErrRaiseTooSmall) {
+		t.Fatalf("expected raise too small got %v", err)
+	}
+	if err := g.AddAction(players[1], ActionRaise, 500); err != nil {
+		t.Fatalf("re-raise: %v", err)
+	}
+}
+
+func TestIncompleteAllInDoesNotReopen(t *testing.T) {
+	g, players := newStartedGame(t, nil, 1000, 1000, 250)
+	g.DealHands()
+	if err := g.AddAction(players[0], ActionRaise, 200); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 200); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if err := g.AddAction(players[2], ActionAllIn, 250); err != nil {
+		t.Fatalf("all-in: %v", err)
+	}
+	for _, a := range g.LegalActions() {
+		if a.Code == ActionRaise || a.Code == ActionAllIn {
+			t.Fatalf("raise should not be offered after a short all-in: %+v", a)
+		}
+	}
+	if err := g.AddAction(players[0], ActionRaise, 500); !errors.Is(err, betting.ErrNotReopened) {
+		t.Fatalf("expected betting not reopened got %v", err)
+	}
+	for _, p := range players[:2] {
+		if err := g.AddAction(p, ActionCheck, 250); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+	if !g.BettingClosed() {
+		t.Fatal("expected betting to close after the calls")
+	}
+}
+
+func TestShortAllInsAddingUpToFullRaise(t *testing.T) {
+	g, players := newStartedGame(t, nil, 250, 1000, 300, 1000)
+	g.DealHands()
+	// the raise to 200 is followed by all-ins of 50 and another 50 more,
+	// which together make a full raise of 100
+	if err := g.AddAction(players[3], ActionRaise, 200); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(players[0], ActionAllIn, 250); err != nil {
+		t.Fatalf("first all-in: %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 250); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if err := g.AddAction(players[2], ActionAllIn, 300); err != nil {
+		t.Fatalf("second all-in: %v", err)
+	}
+	// the raiser faces a full raise since acting and may raise again
+	var raise *LegalAction
+	for _, a := range g.LegalActions() {
+		if a.Code == ActionRaise {
+			a := a
+			raise = &a
+		}
+	}
+	if raise == nil || raise.Min != 400 {
+		t.Fatalf("expected the raiser reopened with a minimum raise to 400 got %+v", raise)
+	}
+	if err := g.AddAction(players[3], ActionCheck, 300); err != nil {
+		t.Fatalf("raiser call: %v", err)
+	}
+	// the small blind only faces the second all-in since calling
+	for _, a := range g.LegalActions() {
+		if a.Code == ActionRaise || a.Code == ActionAllIn {
+			t.Fatalf("raise should not be offered to the caller: %+v", a)
+		}
+	}
+	if err := g.AddAction(players[1], ActionRaise, 500); !errors.Is(err, betting.ErrNotReopened) {
+		t.Fatalf("expected betting not reopened got %v", err)
+	}
+	if err := g.AddAction(players[1], ActionCheck, 300); err != nil {
+		t.Fatalf("small blind call: %v", err)
+	}
+	if !g.BettingClosed() {
+		t.Fatal("expected betting to close after the calls")
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+
+	"pokerDB/pkg/constants"
+	"pokerDB/pkg/rules/evaluation"
+)
+
+// Variant names the poker game a Game deals. The zero value is Texas
+// hold'em.
+type Variant string
+
+// Variants and the number of hole cards they deal.
+const (
+	VariantHoldem Variant = ""       // two hole cards
+	VariantOmaha  Variant = "omaha"  // four hole cards, exactly two of them play
+	VariantOmaha5 Variant = "omaha5" // five hole card Omaha, also called Big O
+	VariantOmaha6 Variant = "omaha6" // six hole card Omaha
+
+	// short-deck hold'em is dealt from a 36 card deck; three of a kind beats
+	// a straight unless the straights variant is played
+	VariantShortDeck          Variant = "shortdeck"
+	VariantShortDeckStraights Variant = "shortdeck-straights"
+
+	// hi-lo games split every pot between the best high hand and the best
+	// eight-or-better low
+	VariantOmahaHiLo  Variant = "omaha-hilo"
+	VariantOmaha5HiLo Variant = "omaha5-hilo"
+)
+
+var variantHoleCards = map[Variant]int{
+	VariantHoldem: 2,
+	VariantOmaha:  4,
+	VariantOmaha5: 5,
+	VariantOmaha6: 6,
+
+	VariantShortDeck:          2,
+	VariantShortDeckStraights: 2,
+
+	VariantOmahaHiLo:  4,
+	VariantOmaha5HiLo: 5,
+}
+
+// HoleCards returns the number of hole cards dealt to every player.
+func (v Variant) HoleCards() int {
+	return variantHoleCards[v]
+}
+
+// Omaha reports whether hands are made of exactly two hole cards and three
+// board cards.
+func (v Variant) Omaha() bool {
+	return v == VariantOmaha || v == VariantOmaha5 || v == VariantOmaha6 || v.HiLo()
+}
+
+// HiLo reports whether pots are split between the best high hand and the
+// best eight-or-better low.
+func (v Variant) HiLo() bool {
+	return v == VariantOmahaHiLo || v == VariantOmaha5HiLo
+}
+
+// ShortDeck returns the hand rankings of a short-deck variant and whether
+// the variant is one.
+func (v Variant) ShortDeck() (evaluation.ShortDeck, bool) {
+	switch v {
+	case VariantShortDeck:
+		return evaluation.ShortDeck{}, true
+	case VariantShortDeckStraights:
+		return evaluation.ShortDeck{StraightsBeatTrips: true}, true
+	}
+	return evaluation.ShortDeck{}, false
+}
+
+// Deck returns the cards the variant is dealt from in their unshuffled
+// order.
+func (v Variant) Deck() []int {
+	if _, ok := v.ShortDeck(); ok {
+		return constants.ShortDeckSequence
+	}
+	return constants.CardSequence
+}
+
+// Validate checks that the variant is known.
+func (v Variant) Validate() error {
+	if _, ok := variantHoleCards[v]; !ok {
+		return fmt.Errorf("unknown variant %q", string(v))
+	}
+	return nil
+}
+
+func (v Variant) String() string {
+	if v == VariantHoldem {
+		return "holdem"
+	}
+	return string(v)
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestOmahaDealsFourHoleCards(t *testing.T) {
+	g, _ := newStartedGame(t, func(g *Game, _ []uuid.UUID) {
+		g.Variant = VariantOmaha
+	}, 500, 500, 500)
+	hands := g.DealHands()
+	if len(hands) != 3 {
+		t.Fatalf("expected three hands got %v", hands)
+	}
+	seen := make(map[int]bool)
+	for _, h := range hands {
+		if len(h) != 4 {
+			t.Fatalf("expected four hole cards got %v", h)
+		}
+		for _, c := range h {
+			if seen[c] {
+				t.Fatalf("card %d dealt twice", c)
+			}
+			seen[c] = true
+		}
+	}
+	replayed, err := Replay(g.ActionLog, g.CardSequence)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed.Variant != VariantOmaha {
+		t.Fatalf("expected the variant to be replayed got %q", replayed.Variant)
+	}
+}
+
+func TestVariantStartChecks(t *testing.T) {
+	g := NewGame(uuid.New(), 9)
+	g.Variant = VariantOmaha6
+	for i := 0; i < 9; i++ {
+		if err := g.BuyIn(uuid.New(), 500); err != nil {
+			t.Fatalf("buyin %d: %v", i, err)
+		}
+	}
+	if err := g.Start(); err == nil {
+		t.Fatal("expected nine players to be too many for six card Omaha")
+	}
+	g.Variant = "stud"
+	if err := g.Start(); err == nil {
+		t.Fatal("expected an unknown variant to fail")
+	}
+}
+
+func TestShortDeckDealsThirtySixCards(t *testing.T) {
+	f, err := NewFairShuffler()
+	if err != nil {
+		t.Fatalf("shuffler: %v", err)
+	}
+	g, _ := newStartedGame(t, func(g *Game, _ []uuid.UUID) {
+		g.Variant = VariantShortDeckStraights
+		g.Shuffler = f
+	}, 500, 500)
+	if len(g.CardSequence) != 36 {
+		t.Fatalf("expected a 36 card deck got %d cards", len(g.CardSequence))
+	}
+	for _, c := range g.CardSequence {
+		// the second to fifth card of every suit are the twos to fives
+		if r := (c - 1) % 13; r >= 1 && r <= 4 {
+			t.Fatalf("card %d is not part of a short deck", c)
+		}
+	}
+	g.DealHands()
+	checkOrCall(t, g)
+	checkOrCall(t, g)
+	if _, err := g.NextStreet(); err != nil {
+		t.Fatalf("flop: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if start := startEntry(t, g); start.Variant != VariantShortDeckStraights {
+		t.Fatalf("expected the variant to be logged got %q", start.Variant)
+	}
+	deck, err := VerifyShuffle(g.ActionLog)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !reflect.DeepEqual(deck, g.CardSequence) {
+		t.Fatalf("expected the dealt deck got %v", deck)
+	}
+}
@@ -0,0 +1,150 @@
+// Package betting implements the no-limit, pot-limit and fixed-limit betting
+// rules shared by the game engine in pkg/models and the action log
+// validator, so both always agree on what a legal raise is.
+package betting
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	// ErrBelowCurrentBet is returned for a raise that does not exceed the
+	// current bet.
+	ErrBelowCurrentBet = errors.New("raise below current bet")
+	// ErrRaiseTooSmall is returned for a raise smaller than the last full
+	// raise on the street.
+	ErrRaiseTooSmall = errors.New("raise too small")
+	// ErrNotReopened is returned when a player who already acted tries to
+	// raise after an incomplete all-in raise.
+	ErrNotReopened = errors.New("betting not reopened")
+	// ErrRaiseTooLarge is returned for a raise above the pot in pot-limit
+	// or above the fixed bet in fixed-limit games.
+	ErrRaiseTooLarge = errors.New("raise above the limit")
+	// ErrRaiseCapped is returned for a raise on a fixed-limit street that
+	// has reached the raise cap.
+	ErrRaiseCapped = errors.New("raises capped")
+)
+
+// Round tracks the bets of one betting round. All amounts are total bets on
+// the street.
+type Round struct {
+	CurrentBet int64 // highest bet on the street
+	LastRaise  int64 // size of the last full raise, the minimum raise increment
+	FullBet    int64 // bet reached by the last full raise or posted blind
+
+	Structure Structure // limits raises above the minimum, see Limits.NewRound
+	RaiseCap  int       // fixed-limit bets and raises allowed, 0 for no cap
+	Bets      int       // full bets and raises made, including posted blinds
+}
+
+// NewRound starts a betting round where the minimum raise is one big blind.
+func NewRound(bigBlind int64) Round {
+	if bigBlind < 1 {
+		bigBlind = 1
+	}
+	return Round{LastRaise: bigBlind}
+}
+
+// MinRaiseTo returns the smallest total bet a full raise has to reach.
+func (r Round) MinRaiseTo() int64 {
+	return r.CurrentBet + r.LastRaise
+}
+
+// CheckRaise verifies that raising to amount is a full raise.
+func (r Round) CheckRaise(amount int64) error {
+	if amount <= r.CurrentBet {
+		return ErrBelowCurrentBet
+	}
+	if amount < r.MinRaiseTo() {
+		return ErrRaiseTooSmall
+	}
+	return nil
+}
+
+// MaxRaiseTo returns the largest total bet a player who has bet bet on the
+// street may raise to while pot chips are in the pot, counting every bet
+// made on the street. A pot-limit raise may add the pot after calling. A
+// fixed-limit raise is exactly one bet, and none is left once the street is
+// capped, so the current bet is returned. No-limit raises are only limited
+// by the player's stack and math.MaxInt64 is returned.
+func (r Round) MaxRaiseTo(pot, bet int64) int64 {
+	switch r.Structure {
+	case PotLimit:
+		call := r.CurrentBet - bet
+		return r.CurrentBet + pot + call
+	case FixedLimit:
+		if r.RaiseCap > 0 && r.Bets >= r.RaiseCap {
+			return r.CurrentBet
+		}
+		return r.MinRaiseTo()
+	}
+	return math.MaxInt64
+}
+
+// CheckLimit verifies that a bet to amount by a player who has bet bet on
+// the street stays within the betting structure's limit.
+func (r Round) CheckLimit(amount, pot, bet int64) error {
+	if amount <= r.CurrentBet {
+		return nil
+	}
+	max := r.MaxRaiseTo(pot, bet)
+	if max <= r.CurrentBet {
+		return ErrRaiseCapped
+	}
+	if amount > max {
+		return ErrRaiseTooLarge
+	}
+	return nil
+}
+
+// Post records a forced bet such as a blind or straddle. A posted blind sets
+// the minimum raise to its own size, so a straddle of two big blinds has to
+// be raised by at least two big blinds. In fixed-limit games the raise stays
+// one bet and a blind of a full bet counts towards the raise cap.
+func (r *Round) Post(amount int64) {
+	if amount > r.CurrentBet {
+		r.CurrentBet = amount
+		r.FullBet = amount
+	}
+	if r.Structure == FixedLimit {
+		if amount >= r.LastRaise*int64(r.Bets+1) {
+			r.Bets++
+		}
+		return
+	}
+	if amount > r.LastRaise {
+		r.LastRaise = amount
+	}
+}
+
+// Apply records a player's total bet of amount, which may be a call, a raise
+// or an all-in. It reports whether the bet completed a full raise, which
+// reopens the betting for players who already acted, see Reopened. An
+// all-in that raises by less than the last full raise moves the current bet
+// but does not reopen the betting or change the minimum raise. Several
+// short all-ins that together raise by a full raise reopen the betting.
+func (r *Round) Apply(amount int64) bool {
+	if amount <= r.CurrentBet {
+		return false
+	}
+	delta := amount - r.CurrentBet
+	r.CurrentBet = amount
+	if amount-r.FullBet < r.LastRaise {
+		return false
+	}
+	if delta >= r.LastRaise {
+		r.LastRaise = delta
+	}
+	r.FullBet = amount
+	r.Bets++
+	return true
+}
+
+// Reopened reports whether a player who has bet bet on the street and
+// already acted may raise again, which requires facing at least a full
+// raise since. A player who called an earlier short all-in is not reopened
+// by the one that completes the raise.
+func (r Round) Reopened(bet int64) bool {
+	return r.CurrentBet-bet >= r.LastRaise
+}
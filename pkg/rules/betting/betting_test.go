@@ -0,0 +1,139 @@
+package betting
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCheckRaise(t *testing.T) {
+	r := NewRound(100)
+	r.Post(50)
+	r.Post(100)
+	if got := r.MinRaiseTo(); got != 200 {
+		t.Fatalf("expected minimum raise to 200 got %d", got)
+	}
+	if err := r.CheckRaise(100); !errors.Is(err, ErrBelowCurrentBet) {
+		t.Fatalf("expected below current bet got %v", err)
+	}
+	if err := r.CheckRaise(150); !errors.Is(err, ErrRaiseTooSmall) {
+		t.Fatalf("expected raise too small got %v", err)
+	}
+	if err := r.CheckRaise(200); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	r := NewRound(100)
+	r.Post(100)
+	if !r.Apply(400) {
+		t.Fatal("raise to 400 should be a full raise")
+	}
+	if r.MinRaiseTo() != 700 {
+		t.Fatalf("expected minimum raise to 700 got %d", r.MinRaiseTo())
+	}
+	if r.Apply(400) {
+		t.Fatal("a call is not a raise")
+	}
+	// an all-in for 550 raises by less than 300
+	if r.Apply(550) {
+		t.Fatal("short all-in should not reopen betting")
+	}
+	if r.CurrentBet != 550 || r.LastRaise != 300 {
+		t.Fatalf("unexpected round %+v", r)
+	}
+}
+
+func TestApplyShortAllIns(t *testing.T) {
+	r := NewRound(100)
+	r.Post(100)
+	if !r.Apply(200) {
+		t.Fatal("raise to 200 should be a full raise")
+	}
+	if r.Apply(250) {
+		t.Fatal("all-in raising by 50 should not reopen betting")
+	}
+	// a second all-in makes the raise since 200 a full raise of 100
+	if !r.Apply(300) {
+		t.Fatal("short all-ins adding up to a full raise should reopen betting")
+	}
+	if r.MinRaiseTo() != 400 {
+		t.Fatalf("expected minimum raise to 400 got %d", r.MinRaiseTo())
+	}
+	if !r.Reopened(200) {
+		t.Fatal("the raise to 200 faces a full raise")
+	}
+	if r.Reopened(250) {
+		t.Fatal("a call of the first all-in faces only 50 more")
+	}
+}
+
+func TestPostStraddle(t *testing.T) {
+	r := NewRound(100)
+	r.Post(50)
+	r.Post(100)
+	r.Post(200)
+	if r.MinRaiseTo() != 400 {
+		t.Fatalf("expected minimum raise to 400 over a straddle got %d", r.MinRaiseTo())
+	}
+}
+
+func TestPotLimitMaxRaise(t *testing.T) {
+	r := Limits{Structure: PotLimit}.NewRound(100, false)
+	r.Post(50)
+	r.Post(100)
+	// calling 100 makes the pot 250, so the first raise is to 350
+	if got := r.MaxRaiseTo(150, 0); got != 350 {
+		t.Fatalf("expected pot raise to 350 got %d", got)
+	}
+	// the small blind owes 50 and may raise to 300
+	if got := r.MaxRaiseTo(150, 50); got != 300 {
+		t.Fatalf("expected pot raise to 300 from the small blind got %d", got)
+	}
+	if err := r.CheckLimit(400, 150, 0); !errors.Is(err, ErrRaiseTooLarge) {
+		t.Fatalf("expected raise too large got %v", err)
+	}
+	if err := r.CheckLimit(350, 150, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFixedLimitCap(t *testing.T) {
+	l := Limits{Structure: FixedLimit, RaiseCap: 4}
+	if bet := l.BetSize(100, true); bet != 200 {
+		t.Fatalf("expected a big bet of 200 got %d", bet)
+	}
+	r := l.NewRound(100, false)
+	r.Post(50)
+	r.Post(100)
+	// the big blind is the first bet, three raises are left
+	for _, to := range []int64{200, 300, 400} {
+		if got := r.MaxRaiseTo(0, 0); got != to || r.MinRaiseTo() != to {
+			t.Fatalf("expected a raise to exactly %d got %d to %d", to, r.MinRaiseTo(), got)
+		}
+		r.Apply(to)
+	}
+	if err := r.CheckLimit(500, 0, 0); !errors.Is(err, ErrRaiseCapped) {
+		t.Fatalf("expected raises capped got %v", err)
+	}
+	if r.MaxRaiseTo(0, 0) != 400 {
+		t.Fatalf("expected no raise above 400 got %d", r.MaxRaiseTo(0, 0))
+	}
+}
+
+func TestLimitsJSON(t *testing.T) {
+	l := Limits{Structure: FixedLimit, SmallBet: 100, BigBet: 200, RaiseCap: 4}
+	data, err := json.Marshal(l)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"structure":2,"small_bet":100,"big_bet":200,"raise_cap":4}`
+	if string(data) != want {
+		t.Fatalf("expected %s got %s", want, data)
+	}
+	var back Limits
+	if err := json.Unmarshal(data, &back); err != nil || back != l {
+		t.Fatalf("expected %+v back got %+v: %v", l, back, err)
+	}
+}
@@ -0,0 +1,92 @@
+package betting
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Structure is the betting structure of a game.
+type Structure int
+
+const (
+	NoLimit    Structure = iota // raises up to the whole stack
+	PotLimit                    // raises up to the size of the pot
+	FixedLimit                  // bets and raises of a fixed size
+)
+
+var structureNames = map[Structure]string{
+	NoLimit:    "nl",
+	PotLimit:   "pl",
+	FixedLimit: "fl",
+}
+
+func (s Structure) String() string {
+	if n, ok := structureNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("structure(%d)", int(s))
+}
+
+// ParseStructure returns the structure named "nl", "pl" or "fl".
+func ParseStructure(name string) (Structure, error) {
+	for s, n := range structureNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return NoLimit, fmt.Errorf("unknown betting structure %q", name)
+}
+
+// ErrBadLimits is returned for limits with an unknown structure or negative
+// bet sizes.
+var ErrBadLimits = errors.New("invalid betting limits")
+
+// Limits configures the betting structure of a game. The zero value is
+// no-limit. The bet sizes and the raise cap only apply to fixed-limit games.
+type Limits struct {
+	Structure Structure `json:"structure"`
+	SmallBet  int64     `json:"small_bet"` // bet on the preflop and flop, the big blind if 0
+	BigBet    int64     `json:"big_bet"`   // bet on the turn and river, twice the small bet if 0
+	RaiseCap  int       `json:"raise_cap"` // bets and raises allowed per street, 0 for no cap
+}
+
+// Validate checks that the limits are usable.
+func (l Limits) Validate() error {
+	if _, ok := structureNames[l.Structure]; !ok {
+		return fmt.Errorf("%w: unknown structure %d", ErrBadLimits, int(l.Structure))
+	}
+	if l.SmallBet < 0 || l.BigBet < 0 || l.RaiseCap < 0 {
+		return fmt.Errorf("%w: bet sizes and raise cap must not be negative", ErrBadLimits)
+	}
+	return nil
+}
+
+// BetSize returns the fixed-limit bet of a street, the big bet on the turn
+// and river when bigBet is set and the small bet otherwise.
+func (l Limits) BetSize(bigBlind int64, bigBet bool) int64 {
+	bet := l.SmallBet
+	if bet == 0 {
+		bet = bigBlind
+	}
+	if bigBet {
+		if l.BigBet > 0 {
+			return l.BigBet
+		}
+		return 2 * bet
+	}
+	return bet
+}
+
+// NewRound starts a betting round under the limits. In fixed-limit games
+// every raise is one bet of the street's size, see BetSize.
+func (l Limits) NewRound(bigBlind int64, bigBet bool) Round {
+	r := NewRound(bigBlind)
+	r.Structure = l.Structure
+	if l.Structure == FixedLimit {
+		if bet := l.BetSize(bigBlind, bigBet); bet > 0 {
+			r.LastRaise = bet
+		}
+		r.RaiseCap = l.RaiseCap
+	}
+	return r
+}
@@ -39,6 +39,20 @@ func NewCardFromId(id uint8) Card {
 	return Card(id)
 }
 
+// NewCardFromInt converts a card numbered 1-52 as used by the card
+// sequences in pkg/models (suits in blocks of 13, ace first) into a Card.
+func NewCardFromInt(c int) Card {
+	r := uint8((c - 1) % 13) // the sequence numbering uses A=0
+	var rank uint8
+	if r == 0 {
+		rank = 12
+	} else {
+		rank = r - 1
+	}
+	suit := uint8((c - 1) / 13)
+	return NewCardFromId((rank << 2) | suit)
+}
+
 func NewCard(name string) Card {
 	return NewCardFromId((rankMap[name[0]] << 2) + suitMap[name[1]])
 }
@@ -23,3 +23,15 @@ func TestCardToString(t *testing.T) {
 	}
 
 }
+
+func TestNewCardFromInt(t *testing.T) {
+	if NewCardFromInt(1).Rank() != 'A' {
+		t.Errorf("NewCardFromInt(1).Rank() = %c, wanted A", NewCardFromInt(1).Rank())
+	}
+	if NewCardFromInt(26).Rank() != 'K' {
+		t.Errorf("NewCardFromInt(26).Rank() = %c, wanted K", NewCardFromInt(26).Rank())
+	}
+	if NewCardFromInt(14).Suit() != NewCardFromInt(26).Suit() {
+		t.Errorf("cards 14 and 26 should share a suit")
+	}
+}
@@ -3,6 +3,10 @@ Package evaluation implements a Texas-Holdem Poker Hand Evaluator based
 on HenryRLee's implementation using a Perfect Hash Algorithm. It
 currently handles hands of 5-7 cards, and uses pre-computed hash tables
 (I reformatted HenryRLee's into go files) to evaluate hands extremely fast.
+EvaluateOmaha ranks Omaha hands, which play exactly two hole cards and
+three board cards, with the same tables. ShortDeck remaps the ranks for
+short-deck hold'em, where a flush beats a full house, and EvaluateLow ranks
+eight-or-better lows for hi-lo games.
 */
 package evaluation
 
@@ -20,18 +24,18 @@ func hashQuinary(q [13]byte, size uint8) int {
 
 func EvaluateHand(h Hand) Rank {
 	if suits[h.getSuitHash()] > 0 {
-		return Rank{flush[h.getSuitBinary()[suits[h.getSuitHash()]-1]]}
+		return Rank{value: flush[h.getSuitBinary()[suits[h.getSuitHash()]-1]]}
 	}
 	hash := hashQuinary(h.getQuinary(), h.Size())
 	switch h.Size() {
 	case 5:
-		return Rank{noflush5[hash]}
+		return Rank{value: noflush5[hash]}
 	case 6:
-		return Rank{noflush6[hash]}
+		return Rank{value: noflush6[hash]}
 	case 7:
-		return Rank{noflush7[hash]}
+		return Rank{value: noflush7[hash]}
 	}
-	return Rank{noflush5[hash]}
+	return Rank{value: noflush5[hash]}
 }
 
 func EvaluateCards(cards ...Card) Rank {
@@ -0,0 +1,119 @@
+package evaluation
+
+import "math/bits"
+
+// LowRank is the rank of an eight-or-better low hand as played in hi-lo
+// split games: five cards of different ranks from eight down, aces playing
+// low, with straights and flushes not counting against the hand. Lower
+// ranks are better. The zero value is a hand that does not qualify for the
+// low.
+type LowRank struct {
+	ranks uint8 // bit n-1 is set for a card of rank n, the ace being one
+}
+
+var lowNames = [8]string{"Ace", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight"}
+
+// lowBit returns the bit of the card in a LowRank, 0 for cards above an
+// eight.
+func lowBit(c Card) uint8 {
+	switch r := c >> 2; {
+	case r == 12:
+		return 1
+	case r <= 6:
+		return 1 << (r + 1)
+	}
+	return 0
+}
+
+// EvaluateLow ranks the best eight-or-better low among five to seven
+// cards, as in stud hi-lo. Pairs do not qualify, so the hand needs five
+// different ranks from eight down.
+func EvaluateLow(cards ...Card) LowRank {
+	var ranks uint8
+	for _, c := range cards {
+		ranks |= lowBit(c)
+	}
+	if bits.OnesCount8(ranks) < 5 {
+		return LowRank{}
+	}
+	// the lowest five ranks make the best low
+	for bits.OnesCount8(ranks) > 5 {
+		ranks &^= 1 << (7 - bits.LeadingZeros8(ranks))
+	}
+	return LowRank{ranks: ranks}
+}
+
+// EvaluateOmahaLow ranks the best eight-or-better low made of exactly two
+// of the hole cards and three of the board cards, as in Omaha hi-lo.
+func EvaluateOmahaLow(hole, board []Card) LowRank {
+	var best LowRank
+	for a := 0; a < len(board); a++ {
+		for b := a + 1; b < len(board); b++ {
+			for c := b + 1; c < len(board); c++ {
+				three := lowBit(board[a]) | lowBit(board[b]) | lowBit(board[c])
+				if bits.OnesCount8(three) != 3 {
+					continue
+				}
+				for i := 0; i < len(hole); i++ {
+					for j := i + 1; j < len(hole); j++ {
+						two := lowBit(hole[i]) | lowBit(hole[j])
+						if bits.OnesCount8(two) != 2 || two&three != 0 {
+							continue
+						}
+						if low := (LowRank{ranks: two | three}); low.Compare(best) < 0 {
+							best = low
+						}
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// Qualifies reports whether the hand makes an eight-or-better low.
+func (l LowRank) Qualifies() bool {
+	return l.ranks != 0
+}
+
+// order compares lows by their highest card, then the next highest and so
+// on, which is the value of their rank bits. Hands without a low come last.
+func (l LowRank) order() int {
+	if l.ranks == 0 {
+		return 1 << 8
+	}
+	return int(l.ranks)
+}
+
+func (a LowRank) Compare(b LowRank) int {
+	return a.order() - b.order()
+}
+
+// DescribeRank names the low by its highest card, such as "Seven Low". The
+// best low, 5-4-3-2-A, is the wheel.
+func (l LowRank) DescribeRank() string {
+	switch l.ranks {
+	case 0:
+		return "No Low"
+	case 0x1f:
+		return "Wheel"
+	}
+	return lowNames[7-bits.LeadingZeros8(l.ranks)] + " Low"
+}
+
+// DescribeSampleHand lists the ranks of the low from the highest, such as
+// "7532A".
+func (l LowRank) DescribeSampleHand() string {
+	hand := make([]byte, 0, 5)
+	for n := 7; n >= 0; n-- {
+		if l.ranks&(1<<n) == 0 {
+			continue
+		}
+		if n == 0 {
+			hand = append(hand, 'A')
+		} else {
+			hand = append(hand, byte('1'+n))
+		}
+	}
+	return string(hand)
+}
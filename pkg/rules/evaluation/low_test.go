@@ -0,0 +1,54 @@
+package evaluation
+
+import "testing"
+
+func TestEvaluateLow(t *testing.T) {
+	cases := []struct {
+		cards  []string
+		rank   string
+		sample string
+	}{
+		// straights and flushes do not spoil a low
+		{[]string{"Ah", "2h", "3h", "4h", "5h", "Kd", "Kc"}, "Wheel", "5432A"},
+		// the lowest five of six low cards play
+		{[]string{"8c", "7d", "6s", "4h", "2c", "Ad", "Qs"}, "Seven Low", "7642A"},
+		// paired low cards count once
+		{[]string{"Ac", "Ad", "2c", "3c", "4d", "9s", "Ts"}, "No Low", ""},
+		{[]string{"9c", "7d", "6s", "4h", "2c"}, "No Low", ""},
+	}
+	for _, c := range cases {
+		l := EvaluateLow(cards(c.cards...)...)
+		if l.DescribeRank() != c.rank || l.DescribeSampleHand() != c.sample {
+			t.Errorf("%v: got %s %q want %s %q", c.cards, l.DescribeRank(), l.DescribeSampleHand(), c.rank, c.sample)
+		}
+	}
+	eightSix := EvaluateLow(cards("8c", "6d", "4s", "3h", "2c")...)
+	eightSeven := EvaluateLow(cards("8c", "7d", "3s", "2h", "Ac")...)
+	if eightSix.Compare(eightSeven) >= 0 {
+		t.Fatal("expected 8-6 to beat 8-7")
+	}
+	if (LowRank{}).Compare(eightSeven) <= 0 || eightSeven.Compare(eightSeven) != 0 {
+		t.Fatal("expected any qualifying low to beat no low")
+	}
+}
+
+func TestEvaluateOmahaLowUsesExactlyTwo(t *testing.T) {
+	cases := []struct {
+		hole, board []string
+		want        string
+	}{
+		{[]string{"Ah", "2c", "Kd", "Ks"}, []string{"3h", "4h", "5c", "Kc", "Qd"}, "5432A"},
+		// a single low card in the hand makes no low
+		{[]string{"Ah", "Kc", "Kd", "Ks"}, []string{"2h", "3h", "4c", "5c", "8d"}, ""},
+		// two low cards on the board make no low
+		{[]string{"Ah", "2c", "3d", "4s"}, []string{"2h", "3h", "Kc", "Qc", "Jd"}, ""},
+		// a low card the hand pairs is no help
+		{[]string{"Ah", "2c", "3d", "8s"}, []string{"2h", "7h", "6c", "Qc", "Jd"}, "7632A"},
+	}
+	for _, c := range cases {
+		l := EvaluateOmahaLow(cards(c.hole...), cards(c.board...))
+		if got := l.DescribeSampleHand(); got != c.want {
+			t.Errorf("%v on %v: got %q want %q", c.hole, c.board, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package evaluation
+
+// worstRank is one past the weakest high hand, 7-5-4-3-2 offsuit.
+const worstRank = 7463
+
+// merge returns the hand holding the cards of both hands, which must not
+// share a card.
+func (h Hand) merge(o Hand) Hand {
+	h.suitHash += o.suitHash
+	for i := range h.suitBinary {
+		h.suitBinary[i] |= o.suitBinary[i]
+	}
+	for i := range h.quinary {
+		h.quinary[i] += o.quinary[i]
+	}
+	h.size += o.size
+	return h
+}
+
+// combos returns a hand for every k cards out of cards, appended to hands.
+func combos(hands []Hand, h Hand, cards []Card, k int) []Hand {
+	if k == 0 {
+		return append(hands, h)
+	}
+	for i := 0; i+k <= len(cards); i++ {
+		hands = combos(hands, h.AddCard(cards[i]), cards[i+1:], k-1)
+	}
+	return hands
+}
+
+// EvaluateOmaha ranks the best five card hand made of exactly two of the
+// hole cards and three of the board cards, as in Omaha. The hole may hold
+// four cards, or five and six for Big O and six card Omaha, and the board
+// three to five cards. Every combination is looked up in the same perfect
+// hash tables as EvaluateHand, at most 150 for six hole cards and a full
+// board. A Rank with no value is returned when hole holds fewer than two or
+// board fewer than three cards.
+func EvaluateOmaha(hole, board []Card) Rank {
+	if len(hole) < 2 || len(board) < 3 {
+		return Rank{}
+	}
+	// at most 15 pairs from six hole cards and 10 triples from the board
+	var pairBuf [15]Hand
+	var tripleBuf [10]Hand
+	boards := combos(tripleBuf[:0], Hand{}, board, 3)
+	best := uint16(worstRank)
+	for _, p := range combos(pairBuf[:0], Hand{}, hole, 2) {
+		for _, b := range boards {
+			if v := EvaluateHand(p.merge(b)).value; v < best {
+				best = v
+			}
+		}
+	}
+	return Rank{value: best}
+}
@@ -0,0 +1,66 @@
+package evaluation
+
+import "testing"
+
+func cards(names ...string) []Card {
+	out := make([]Card, len(names))
+	for i, n := range names {
+		out[i] = NewCard(n)
+	}
+	return out
+}
+
+func TestEvaluateOmahaUsesExactlyTwo(t *testing.T) {
+	cases := []struct {
+		hole, board []string
+		want        string
+	}{
+		// four hearts on the board make no flush with a single heart
+		{[]string{"Ah", "Kc", "Qd", "Js"}, []string{"2h", "5h", "8h", "9h", "Tc"}, "Straight"},
+		// a pair on the board and trips in the hand are not a full house
+		{[]string{"Ac", "Ad", "As", "2c"}, []string{"Kh", "Kd", "7s", "8c", "3d"}, "Two Pair"},
+		// two hearts from the hand complete the flush
+		{[]string{"Ah", "3h", "Qd", "Js"}, []string{"2h", "5h", "8h", "9c", "Tc"}, "Flush"},
+		// five hole cards for Big O
+		{[]string{"9s", "8s", "Ac", "Ad", "2c"}, []string{"7s", "6s", "5s", "Kd", "Kc"}, "Straight Flush"},
+	}
+	for _, c := range cases {
+		r := EvaluateOmaha(cards(c.hole...), cards(c.board...))
+		if got := r.DescribeCategory(); got != c.want {
+			t.Errorf("%v on %v: got %s want %s", c.hole, c.board, got, c.want)
+		}
+	}
+}
+
+func TestEvaluateOmahaMatchesBestCombination(t *testing.T) {
+	hole := cards("Ah", "Kh", "7c", "7d", "2s", "3s")
+	board := cards("7h", "Qh", "Jd", "4s", "5s")
+	best := EvaluateCards(hole[2], hole[3], board[0], board[2], board[4])
+	for i := range hole {
+		for j := i + 1; j < len(hole); j++ {
+			for a := range board {
+				for b := a + 1; b < len(board); b++ {
+					for c := b + 1; c < len(board); c++ {
+						if r := EvaluateCards(hole[i], hole[j], board[a], board[b], board[c]); r.Compare(best) < 0 {
+							best = r
+						}
+					}
+				}
+			}
+		}
+	}
+	if got := EvaluateOmaha(hole, board); got != best {
+		t.Fatalf("got %s want %s", got.DescribeRank(), best.DescribeRank())
+	}
+	if got := EvaluateOmaha(hole[:1], board); got.GetValue() != 0 {
+		t.Fatalf("expected no rank with one hole card got %d", got.GetValue())
+	}
+}
+
+func BenchmarkEvaluateOmaha(b *testing.B) {
+	hole := cards("Ah", "Kh", "7c", "7d")
+	board := cards("7h", "Qh", "Jd", "4s", "5s")
+	for i := 0; i < b.N; i++ {
+		EvaluateOmaha(hole, board)
+	}
+}
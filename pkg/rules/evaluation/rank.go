@@ -75,10 +75,20 @@ func IsFlush(rank uint16) bool {
 
 type Rank struct {
 	value uint16
+	key   uint16 // position among short-deck hands, 0 for standard rankings
+}
+
+// order returns the position of the rank in the ranking it was evaluated
+// under, lower being better.
+func (r Rank) order() uint16 {
+	if r.key != 0 {
+		return r.key
+	}
+	return r.value
 }
 
 func (a Rank) Compare(b Rank) int {
-	return int(a.value) - int(b.value)
+	return int(a.order()) - int(b.order())
 }
 
 func (r Rank) GetValue() uint16 {
@@ -94,10 +104,16 @@ func (r Rank) DescribeCategory() string {
 }
 
 func (r Rank) DescribeRank() string {
+	if d, ok := shortDeckDescriptions[r.value]; ok && r.key != 0 {
+		return d[1]
+	}
 	return DescribeRank(r.GetValue())
 }
 
 func (r Rank) DescribeSampleHand() string {
+	if d, ok := shortDeckDescriptions[r.value]; ok && r.key != 0 {
+		return d[0]
+	}
 	return DescribeSampleHand(r.GetValue())
 }
 
@@ -0,0 +1,95 @@
+package evaluation
+
+// ShortDeck ranks hands dealt from the 36 card short deck, which has no
+// twos to fives. A-6-7-8-9 is the lowest straight, a flush beats a full
+// house and three of a kind beats a straight unless StraightsBeatTrips is
+// set. The standard tables rank the hand and the result is remapped, so a
+// short-deck rank describes the same hand as a standard one and only
+// compares differently.
+type ShortDeck struct {
+	StraightsBeatTrips bool `json:"straights_beat_trips"`
+}
+
+// Standard rank values of the hands short-deck rankings move.
+const (
+	wheelStraightFlush = 10   // 5-4-3-2-A straight flush
+	wheelStraight      = 1609 // 5-4-3-2-A straight
+)
+
+// shortWheel holds the rank bits of A-6-7-8-9, the short-deck wheel.
+const shortWheel = 1<<12 | 1<<7 | 1<<6 | 1<<5 | 1<<4
+
+// shortDeckDescriptions describe the A-6-7-8-9 straights, which take the
+// values of the 5-4-3-2-A straights that cannot be made from a short deck.
+var shortDeckDescriptions = map[uint16][2]string{
+	wheelStraightFlush: {"9876A", "Nine-High Straight Flush"},
+	wheelStraight:      {"9876A", "Nine-High Straight"},
+}
+
+// EvaluateCards ranks 5 to 7 short-deck cards.
+func (s ShortDeck) EvaluateCards(cards ...Card) Rank {
+	return s.EvaluateHand(*NewHand(cards...))
+}
+
+// EvaluateHand ranks a hand of 5 to 7 short-deck cards.
+func (s ShortDeck) EvaluateHand(h Hand) Rank {
+	v := EvaluateHand(h).value
+	var ranks int
+	for _, bits := range h.suitBinary {
+		if bits&shortWheel == shortWheel && v > wheelStraightFlush {
+			v = wheelStraightFlush
+		}
+		ranks |= bits
+	}
+	if ranks&shortWheel == shortWheel && s.order(wheelStraight) < s.order(v) {
+		v = wheelStraight
+	}
+	if GetRankCategory(v) == Straight && !s.StraightsBeatTrips {
+		// seven cards can hold a straight and three of a kind at once
+		if t, ok := h.trips(); ok {
+			v = t
+		}
+	}
+	return Rank{value: v, key: s.order(v)}
+}
+
+// trips returns the value of the best three of a kind in the hand, ignoring
+// any straight, flush or full house it also makes.
+func (h Hand) trips() (uint16, bool) {
+	var q [13]byte
+	found, kickers := false, 0
+	for i := len(h.quinary) - 1; i >= 0; i-- {
+		switch {
+		case h.quinary[i] >= 3 && !found:
+			q[i], found = 3, true
+		case h.quinary[i] > 0 && kickers < 2:
+			q[i] = 1
+			kickers++
+		}
+	}
+	if !found || kickers < 2 {
+		return 0, false
+	}
+	return noflush5[hashQuinary(q, 5)], true
+}
+
+// order returns the position of a standard rank value among short-deck
+// hands. Flushes move ahead of full houses and, unless straights beat
+// trips, three of a kind ahead of straights.
+func (s ShortDeck) order(v uint16) uint16 {
+	switch GetRankCategory(v) {
+	case Flush:
+		return v - 156 // full houses take 167 to 322
+	case FullHouse:
+		return v + 1277 // flushes take 323 to 1599
+	case Straight:
+		if !s.StraightsBeatTrips {
+			return v + 858 // three of a kind take 1610 to 2467
+		}
+	case ThreeOfAKind:
+		if !s.StraightsBeatTrips {
+			return v - 10 // straights take 1600 to 1609
+		}
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package evaluation
+
+import "testing"
+
+func TestShortDeckRankings(t *testing.T) {
+	flush := cards("Ah", "Jh", "9h", "7h", "6h")
+	fullHouse := cards("Ac", "Ad", "As", "Kc", "Kd")
+	straight := cards("Tc", "9d", "8s", "7h", "6c")
+	wheel := cards("Ac", "6d", "7s", "8h", "9c")
+	trips := cards("6c", "6d", "6s", "7h", "8c")
+	rules := ShortDeck{}
+
+	if EvaluateCards(fullHouse...).Compare(EvaluateCards(flush...)) >= 0 {
+		t.Fatal("a full house beats a flush with a full deck")
+	}
+	if rules.EvaluateCards(flush...).Compare(rules.EvaluateCards(fullHouse...)) >= 0 {
+		t.Fatal("expected a flush to beat a full house")
+	}
+	if rules.EvaluateCards(trips...).Compare(rules.EvaluateCards(straight...)) >= 0 {
+		t.Fatal("expected three of a kind to beat a straight")
+	}
+	r := rules.EvaluateCards(wheel...)
+	if r.DescribeRank() != "Nine-High Straight" || r.Compare(rules.EvaluateCards(straight...)) <= 0 {
+		t.Fatalf("expected A-6-7-8-9 to be the lowest straight got %s", r.DescribeRank())
+	}
+	if r := rules.EvaluateCards(cards("As", "6s", "7s", "8s", "9s")...); r.DescribeRank() != "Nine-High Straight Flush" {
+		t.Fatalf("expected a nine-high straight flush got %s", r.DescribeRank())
+	}
+
+	straights := ShortDeck{StraightsBeatTrips: true}
+	if straights.EvaluateCards(straight...).Compare(straights.EvaluateCards(trips...)) >= 0 {
+		t.Fatal("expected a straight to beat three of a kind")
+	}
+	if straights.EvaluateCards(wheel...).Compare(straights.EvaluateCards(trips...)) >= 0 {
+		t.Fatal("expected A-6-7-8-9 to beat three of a kind")
+	}
+}
+
+func TestShortDeckSevenCards(t *testing.T) {
+	// seven cards making both a straight and three of a kind
+	hand := cards("7c", "7d", "7s", "8h", "9c", "Td", "Js")
+	if got := (ShortDeck{}).EvaluateCards(hand...).DescribeCategory(); got != "Three Of a Kind" {
+		t.Fatalf("expected three of a kind got %s", got)
+	}
+	if got := (ShortDeck{StraightsBeatTrips: true}).EvaluateCards(hand...).DescribeCategory(); got != "Straight" {
+		t.Fatalf("expected a straight got %s", got)
+	}
+	// the ace plays low for A-6-7-8-9 but high in a pair
+	hand = cards("Ac", "Ad", "6s", "7h", "8c", "9d", "Ks")
+	if got := (ShortDeck{}).EvaluateCards(hand...).DescribeRank(); got != "Nine-High Straight" {
+		t.Fatalf("expected a nine-high straight got %s", got)
+	}
+}
@@ -1,9 +1,258 @@
+// Package play resolves hands played with a models.Game.
 package play
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
 	"pokerDB/pkg/models"
+	"pokerDB/pkg/rules/evaluation"
 )
 
+// GameResolver settles the hands of a game at showdown.
 type GameResolver struct {
 	Game *models.Game `json:"game"`
 }
+
+// PotResult describes how a single pot, or its share of one board when the
+// hand was run more than once, was split at showdown.
+type PotResult struct {
+	Pot     models.Pot                    `json:"pot"`
+	Run     int                           `json:"run"`
+	Board   models.IntSlice               `json:"board"`
+	Winners []uuid.UUID                   `json:"winners"`
+	Shares  map[uuid.UUID]int64           `json:"shares"`
+	Ranks   map[uuid.UUID]evaluation.Rank `json:"-"`
+
+	// hi-lo pots also name the players sharing the low half, none when no
+	// low qualified, and the lows of the players ranked
+	LowWinners []uuid.UUID                      `json:"low_winners,omitempty"`
+	Lows       map[uuid.UUID]evaluation.LowRank `json:"-"`
+}
+
+// NewGameResolver returns a resolver for the given game.
+func NewGameResolver(g *models.Game) *GameResolver {
+	return &GameResolver{Game: g}
+}
+
+// Resolve finishes the current hand. Remaining community cards are dealt
+// when all betting is complete, the live players' hole cards are ranked
+// against the board and every pot less its rake is awarded to its best
+// eligible hands.
+// Split pots are divided evenly with odd chips going to the winners closest
+// to the left of the button. When the players agreed to run it more than
+// once each pot is divided evenly between the boards, odd chips going to
+// the first board, and every board is ranked on its own. In hi-lo games
+// the best high hand and the best eight-or-better low split each pot, the
+// odd chip going to the high, and tied lows share the low half; without a
+// qualifying low the high hand takes the whole pot. The resulting balances
+// are written to the game's ledgers.
+func (r *GameResolver) Resolve() ([]PotResult, error) {
+	g := r.Game
+	if g == nil {
+		return nil, errors.New("no game to resolve")
+	}
+	boards, err := g.RunOut()
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach showdown: %w", err)
+	}
+
+	order := g.HandOrder()
+	pots := g.Pots()
+	results := []PotResult{}
+	awards := make([]map[uuid.UUID]int64, len(pots))
+	ranks := make([]map[uuid.UUID]evaluation.Rank, len(boards))
+	lows := make([]map[uuid.UUID]evaluation.LowRank, len(boards))
+	for i := range ranks {
+		ranks[i] = make(map[uuid.UUID]evaluation.Rank)
+		lows[i] = make(map[uuid.UUID]evaluation.LowRank)
+	}
+	for i, pot := range pots {
+		awards[i] = make(map[uuid.UUID]int64)
+		amounts := splitRuns(pot.Amount, len(boards))
+		rakes := splitRuns(pot.Rake, len(boards))
+		for run, board := range boards {
+			if len(pot.Eligible) > 1 {
+				for _, pid := range pot.Eligible {
+					if _, ok := ranks[run][pid]; !ok {
+						rank, err := r.rank(pid, board)
+						if err != nil {
+							return nil, err
+						}
+						ranks[run][pid] = rank
+						if g.Variant.HiLo() {
+							lows[run][pid] = r.low(pid, board)
+						}
+					}
+				}
+			}
+			winners := bestHands(pot.Eligible, ranks[run])
+			net := amounts[run] - rakes[run]
+			shares := split(net, winners, order)
+			lowWinners := bestLows(pot.Eligible, lows[run])
+			if len(lowWinners) > 0 {
+				shares = split(net-net/2, winners, order)
+				for pid, amount := range split(net/2, lowWinners, order) {
+					shares[pid] += amount
+				}
+			}
+			potRanks := make(map[uuid.UUID]evaluation.Rank)
+			var potLows map[uuid.UUID]evaluation.LowRank
+			if g.Variant.HiLo() {
+				potLows = make(map[uuid.UUID]evaluation.LowRank)
+			}
+			for _, pid := range pot.Eligible {
+				if rank, ok := ranks[run][pid]; ok {
+					potRanks[pid] = rank
+				}
+				if low, ok := lows[run][pid]; ok {
+					potLows[pid] = low
+				}
+			}
+			runPot := models.Pot{Amount: amounts[run], Eligible: pot.Eligible, Rake: rakes[run]}
+			results = append(results, PotResult{Pot: runPot, Run: run + 1, Board: board, Winners: winners, Shares: shares, Ranks: potRanks, LowWinners: lowWinners, Lows: potLows})
+			for pid, amount := range shares {
+				awards[i][pid] += amount
+			}
+		}
+	}
+	if err := g.AwardPots(awards); err != nil {
+		return nil, err
+	}
+	if err := r.updateLedgers(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// rank evaluates a player's hole cards together with a board. Omaha hands
+// use exactly two hole cards and three board cards and short-deck hands are
+// ranked by the short-deck rules of the variant.
+func (r *GameResolver) rank(playerID uuid.UUID, board models.IntSlice) (evaluation.Rank, error) {
+	hole := r.Game.HoleCards(playerID)
+	if len(hole) == 0 {
+		return evaluation.Rank{}, fmt.Errorf("no hole cards for player %s", playerID)
+	}
+	if r.Game.Variant.Omaha() {
+		return evaluation.EvaluateOmaha(toCards(hole), toCards(board)), nil
+	}
+	cards := make([]evaluation.Card, 0, len(hole)+len(board))
+	for _, c := range hole {
+		cards = append(cards, evaluation.NewCardFromInt(c))
+	}
+	for _, c := range board {
+		cards = append(cards, evaluation.NewCardFromInt(c))
+	}
+	if rules, ok := r.Game.Variant.ShortDeck(); ok {
+		return rules.EvaluateCards(cards...), nil
+	}
+	return evaluation.EvaluateCards(cards...), nil
+}
+
+// low evaluates a player's eight-or-better low in a hi-lo game, made of
+// exactly two hole cards and three board cards.
+func (r *GameResolver) low(playerID uuid.UUID, board models.IntSlice) evaluation.LowRank {
+	return evaluation.EvaluateOmahaLow(toCards(r.Game.HoleCards(playerID)), toCards(board))
+}
+
+// toCards converts cards numbered 1-52 into evaluation cards.
+func toCards(cards []int) []evaluation.Card {
+	out := make([]evaluation.Card, len(cards))
+	for i, c := range cards {
+		out[i] = evaluation.NewCardFromInt(c)
+	}
+	return out
+}
+
+// updateLedgers stores every player's balance in the game's ledgers so the
+// chips won and lost can be seen before the game ends.
+func (r *GameResolver) updateLedgers() error {
+	ledgers, err := r.Game.Settle()
+	if err != nil {
+		return err
+	}
+	r.Game.SetLedgers(ledgers)
+	return nil
+}
+
+// bestHands returns the eligible players holding the best ranked hand. A
+// single eligible player wins without being ranked.
+func bestHands(eligible []uuid.UUID, ranks map[uuid.UUID]evaluation.Rank) []uuid.UUID {
+	if len(eligible) <= 1 {
+		return eligible
+	}
+	winners := []uuid.UUID{}
+	var best evaluation.Rank
+	for _, pid := range eligible {
+		rank := ranks[pid]
+		switch {
+		case len(winners) == 0 || rank.Compare(best) < 0:
+			best = rank
+			winners = []uuid.UUID{pid}
+		case rank.Compare(best) == 0:
+			winners = append(winners, pid)
+		}
+	}
+	return winners
+}
+
+// bestLows returns the ranked players holding the best qualifying low, none
+// when no low qualifies.
+func bestLows(eligible []uuid.UUID, lows map[uuid.UUID]evaluation.LowRank) []uuid.UUID {
+	var winners []uuid.UUID
+	var best evaluation.LowRank
+	for _, pid := range eligible {
+		low, ok := lows[pid]
+		if !ok || !low.Qualifies() {
+			continue
+		}
+		switch {
+		case len(winners) == 0 || low.Compare(best) < 0:
+			best = low
+			winners = []uuid.UUID{pid}
+		case low.Compare(best) == 0:
+			winners = append(winners, pid)
+		}
+	}
+	return winners
+}
+
+// splitRuns divides a pot evenly between the boards. Odd chips go to the
+// first boards.
+func splitRuns(amount int64, runs int) []int64 {
+	amounts := make([]int64, runs)
+	for i := range amounts {
+		amounts[i] = amount / int64(runs)
+		if int64(i) < amount%int64(runs) {
+			amounts[i]++
+		}
+	}
+	return amounts
+}
+
+// split divides amount between the winners. Odd chips go one at a time to
+// the winners in order, which starts left of the button.
+func split(amount int64, winners []uuid.UUID, order []uuid.UUID) map[uuid.UUID]int64 {
+	shares := make(map[uuid.UUID]int64)
+	if len(winners) == 0 {
+		return shares
+	}
+	each := amount / int64(len(winners))
+	odd := amount % int64(len(winners))
+	isWinner := make(map[uuid.UUID]bool)
+	for _, pid := range winners {
+		shares[pid] = each
+		isWinner[pid] = true
+	}
+	for _, pid := range order {
+		if odd == 0 {
+			break
+		}
+		if isWinner[pid] {
+			shares[pid]++
+			odd--
+		}
+	}
+	return shares
+}
@@ -0,0 +1,426 @@
+package play
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"pokerDB/pkg/models"
+)
+
+// stackedShuffler deals its cards first, followed by the rest of the deck
+// in order. Zero entries are filled with unused cards.
+type stackedShuffler []int
+
+func (s stackedShuffler) Shuffle(cards []int) error {
+	used := make(map[int]bool)
+	for _, c := range s {
+		used[c] = true
+	}
+	rest := []int{}
+	for _, c := range cards {
+		if !used[c] {
+			rest = append(rest, c)
+		}
+	}
+	copy(cards, s)
+	for i := range cards {
+		if i >= len(s) || cards[i] == 0 {
+			cards[i], rest = rest[0], rest[1:]
+		}
+	}
+	return nil
+}
+
+func (s stackedShuffler) Name() string {
+	return "stacked"
+}
+
+// stackDeck lays out cards given as two hole cards per player in seat
+// order followed by five card boards in dealing order: hole cards one at a
+// time starting left of the button on the first seat, and a burn card
+// before every street.
+func stackDeck(cards []int, players int) stackedShuffler {
+	return stackHoles(cards, players, 2)
+}
+
+// stackHoles lays out cards like stackDeck for games dealing hole hole
+// cards to every player.
+func stackHoles(cards []int, players, hole int) stackedShuffler {
+	deck := stackedShuffler{}
+	for k := 0; k < hole; k++ {
+		for i := 1; i <= players; i++ {
+			deck = append(deck, cards[(i%players)*hole+k])
+		}
+	}
+	for board := cards[hole*players:]; len(board) > 0; board = board[5:] {
+		deck = append(deck, 0)
+		deck = append(deck, board[:3]...)
+		deck = append(deck, 0, board[3], 0, board[4])
+	}
+	return deck
+}
+
+// startGame starts a game for the given stacks with a deck stacked from
+// cards, see stackDeck, so hands are dealt deterministically.
+func startGame(t *testing.T, sb, bb int64, cards []int, stacks ...int64) (*models.Game, []uuid.UUID) {
+	t.Helper()
+	g := models.NewGame(uuid.New(), len(stacks))
+	g.SmallBlind = sb
+	g.BigBlind = bb
+	g.Shuffler = stackDeck(cards, len(stacks))
+	players := make([]uuid.UUID, len(stacks))
+	for i, s := range stacks {
+		players[i] = uuid.New()
+		if err := g.BuyIn(players[i], s); err != nil {
+			t.Fatalf("buyin %d: %v", i, err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	return g, players
+}
+
+func act(t *testing.T, g *models.Game, pid uuid.UUID, code string, amount int64) {
+	t.Helper()
+	if err := g.AddAction(pid, code, amount); err != nil {
+		t.Fatalf("%s %d: %v", models.ActionToWord(code), amount, err)
+	}
+}
+
+func TestResolveAllInRunout(t *testing.T) {
+	// A♠ A♥ against K♠ K♥ on 2♣ 7♦ 9♥ 4♠ 5♦
+	cards := []int{1, 14, 13, 26, 41, 33, 22, 4, 31}
+	g, players := startGame(t, 50, 100, cards, 500, 500)
+	act(t, g, players[0], models.ActionAllIn, 500)
+	act(t, g, players[1], models.ActionAllIn, 500)
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(g.Board) != 5 {
+		t.Fatalf("expected the board to be run out got %v", g.Board)
+	}
+	if len(results) != 1 || len(results[0].Winners) != 1 || results[0].Winners[0] != players[0] {
+		t.Fatalf("expected aces to win got %+v", results)
+	}
+	if g.Stacks[players[0]] != 1000 || g.Stacks[players[1]] != 0 {
+		t.Fatalf("unexpected stacks %d %d", g.Stacks[players[0]], g.Stacks[players[1]])
+	}
+	balances := map[uuid.UUID]int64{}
+	for _, l := range g.Ledgers {
+		balances[l.PlayerID] = l.Balance
+	}
+	if balances[players[0]] != 500 || balances[players[1]] != -500 {
+		t.Fatalf("unexpected ledgers %+v", g.Ledgers)
+	}
+}
+
+func TestResolveSplitPotOddChip(t *testing.T) {
+	// both remaining players play the broadway straight on the board
+	cards := []int{15, 29, 43, 19, 28, 42, 1, 26, 38, 50, 10}
+	g, players := startGame(t, 25, 100, cards, 1000, 1000, 1000)
+	act(t, g, players[0], models.ActionCheck, 100)
+	act(t, g, players[1], models.ActionFold, 0)
+	act(t, g, players[2], models.ActionCheck, 0)
+	for g.CurrentStreet != models.StreetShowdown {
+		if _, err := g.NextStreet(); err != nil {
+			t.Fatalf("next street: %v", err)
+		}
+		for !g.BettingClosed() {
+			act(t, g, g.ActionOn(), models.ActionCheck, 0)
+		}
+	}
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Winners) != 2 {
+		t.Fatalf("expected a split pot got %+v", results)
+	}
+	// the odd chip goes to the big blind, the first winner left of the button
+	if results[0].Shares[players[2]] != 113 || results[0].Shares[players[0]] != 112 {
+		t.Fatalf("unexpected shares %v", results[0].Shares)
+	}
+	if g.Stacks[players[2]] != 1013 || g.Stacks[players[0]] != 1012 {
+		t.Fatalf("unexpected stacks %d %d", g.Stacks[players[2]], g.Stacks[players[0]])
+	}
+}
+
+func TestResolveSidePots(t *testing.T) {
+	// player 1 holds aces, player 2 kings and player 3 queens
+	cards := []int{1, 14, 13, 26, 12, 25, 41, 33, 22, 4, 31}
+	g, players := startGame(t, 50, 100, cards, 100, 300, 500)
+	act(t, g, players[0], models.ActionAllIn, 100)
+	act(t, g, players[1], models.ActionAllIn, 300)
+	act(t, g, players[2], models.ActionCheck, 300)
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected main and side pot got %+v", results)
+	}
+	if results[0].Winners[0] != players[0] || results[0].Pot.Amount != 300 {
+		t.Errorf("main pot should go to aces: %+v", results[0])
+	}
+	if results[1].Winners[0] != players[1] || results[1].Pot.Amount != 400 {
+		t.Errorf("side pot should go to kings: %+v", results[1])
+	}
+	want := []int64{300, 400, 200}
+	for i, p := range players {
+		if g.Stacks[p] != want[i] {
+			t.Errorf("player %d stack %d want %d", i, g.Stacks[p], want[i])
+		}
+	}
+}
+
+func TestResolveFoldWin(t *testing.T) {
+	g, players := startGame(t, 50, 100, []int{1, 2, 3, 4, 5, 6}, 1000, 1000, 1000)
+	act(t, g, players[0], models.ActionRaise, 300)
+	act(t, g, players[1], models.ActionFold, 0)
+	act(t, g, players[2], models.ActionFold, 0)
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(g.Board) != 0 {
+		t.Fatal("no board should be dealt when everybody folded")
+	}
+	if results[0].Winners[0] != players[0] || g.Stacks[players[0]] != 1150 {
+		t.Fatalf("expected raiser to win blinds, stack %d", g.Stacks[players[0]])
+	}
+}
+
+func TestResolveBeforeBettingComplete(t *testing.T) {
+	g, _ := startGame(t, 50, 100, []int{1, 2, 3, 4}, 1000, 1000)
+	if _, err := NewGameResolver(g).Resolve(); err == nil {
+		t.Fatal("expected error while betting is open")
+	}
+}
+
+func TestResolveRunItTwice(t *testing.T) {
+	// A♠ A♥ against K♠ K♥; the first board is a blank, the second gives
+	// the kings quads
+	cards := []int{1, 14, 13, 26, 41, 33, 22, 4, 31, 39, 52, 35, 17, 7}
+	g, players := startGame(t, 50, 100, cards, 500, 500)
+	g.AllowRunItTwice = true
+	act(t, g, players[0], models.ActionAllIn, 500)
+	act(t, g, players[1], models.ActionAllIn, 500)
+	for _, p := range players {
+		if err := g.RunItTwice(p, 2); err != nil {
+			t.Fatalf("run it twice: %v", err)
+		}
+	}
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(g.Boards) != 2 || len(g.Boards[1]) != 5 {
+		t.Fatalf("expected two full boards got %v", g.Boards)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected a result per board got %+v", results)
+	}
+	if results[0].Run != 1 || results[0].Winners[0] != players[0] || results[0].Pot.Amount != 500 {
+		t.Errorf("aces should win the first board: %+v", results[0])
+	}
+	if results[1].Run != 2 || results[1].Winners[0] != players[1] || results[1].Pot.Amount != 500 {
+		t.Errorf("kings should win the second board: %+v", results[1])
+	}
+	if g.Stacks[players[0]] != 500 || g.Stacks[players[1]] != 500 {
+		t.Fatalf("unexpected stacks %d %d", g.Stacks[players[0]], g.Stacks[players[1]])
+	}
+}
+
+func TestResolveRunItTwiceDisagree(t *testing.T) {
+	cards := []int{1, 14, 13, 26, 41, 33, 22, 4, 31, 39, 52, 35, 17, 7}
+	g, players := startGame(t, 50, 100, cards, 500, 500)
+	g.AllowRunItTwice = true
+	act(t, g, players[0], models.ActionAllIn, 500)
+	act(t, g, players[1], models.ActionAllIn, 500)
+	if err := g.RunItTwice(players[0], 2); err != nil {
+		t.Fatalf("run it twice: %v", err)
+	}
+	if err := g.RunItTwice(players[1], 1); err != nil {
+		t.Fatalf("run it once: %v", err)
+	}
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 1 || g.Boards != nil {
+		t.Fatalf("expected a single board got %+v", results)
+	}
+	if g.Stacks[players[0]] != 1000 {
+		t.Fatalf("expected aces to scoop got %d", g.Stacks[players[0]])
+	}
+}
+
+func TestResolveOmahaUsesTwoHoleCards(t *testing.T) {
+	// four cards of one suit on the board give player 1 a flush with the
+	// ace of that suit in hold'em, but Omaha hands play exactly two hole
+	// cards, so player 1 holds a pair of threes and player 2 the straight
+	// queen-jack with ten-nine-eight
+	cards := []int{
+		1, 16, 29, 43,
+		25, 37, 39, 28,
+		2, 5, 8, 9, 23,
+	}
+	g := models.NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.Variant = models.VariantOmaha
+	g.Shuffler = stackHoles(cards, 2, 4)
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 500); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	hands := g.DealHands()
+	if len(hands) != 2 || len(hands[0]) != 4 || len(hands[1]) != 4 {
+		t.Fatalf("expected four hole cards each got %v", hands)
+	}
+	act(t, g, players[0], models.ActionAllIn, 500)
+	act(t, g, players[1], models.ActionAllIn, 500)
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Winners) != 1 || results[0].Winners[0] != players[1] {
+		t.Fatalf("expected the straight to win got %+v", results)
+	}
+	if got := results[0].Ranks[players[0]].DescribeCategory(); got != "One Pair" {
+		t.Fatalf("expected player 1 to hold one pair got %s", got)
+	}
+}
+
+func TestResolveShortDeckFlushBeatsFullHouse(t *testing.T) {
+	// player 1 holds aces full of nines and player 2 a flush, which only
+	// wins with a short deck
+	cards := []int{
+		14, 27,
+		11, 46,
+		1, 9, 22, 6, 7,
+	}
+	g := models.NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.Variant = models.VariantShortDeck
+	g.Shuffler = stackDeck(cards, 2)
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 500); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	act(t, g, players[0], models.ActionAllIn, 500)
+	act(t, g, players[1], models.ActionAllIn, 500)
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Winners) != 1 || results[0].Winners[0] != players[1] {
+		t.Fatalf("expected the flush to win got %+v", results)
+	}
+	if got := results[0].Ranks[players[0]].DescribeCategory(); got != "Full House" {
+		t.Fatalf("expected player 1 to hold a full house got %s", got)
+	}
+}
+
+func TestResolveHiLoQuartersTiedLows(t *testing.T) {
+	// player 1 holds trip kings for the high, players 2 and 3 both hold
+	// ace-four for a seven low on 2♣ 3♦ 7♥ K♣ K♦
+	cards := []int{
+		39, 12, 25, 11,
+		14, 30, 9, 10,
+		40, 43, 22, 23,
+		2, 16, 33, 13, 26,
+	}
+	g := models.NewGame(uuid.New(), 3)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.Variant = models.VariantOmahaHiLo
+	g.Shuffler = stackHoles(cards, 3, 4)
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 500); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	for _, p := range players {
+		act(t, g, p, models.ActionAllIn, 500)
+	}
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Winners) != 1 || results[0].Winners[0] != players[0] {
+		t.Fatalf("expected player 1 to win the high got %+v", results)
+	}
+	if len(results[0].LowWinners) != 2 || results[0].Lows[players[1]].DescribeSampleHand() != "7432A" {
+		t.Fatalf("expected players 2 and 3 to tie for the low got %+v", results[0])
+	}
+	want := map[uuid.UUID]int64{players[0]: 750, players[1]: 375, players[2]: 375}
+	for pid, amount := range want {
+		if results[0].Shares[pid] != amount {
+			t.Errorf("expected %s to win %d got %d", pid, amount, results[0].Shares[pid])
+		}
+	}
+}
+
+func TestResolveHiLoWithoutLow(t *testing.T) {
+	// no low qualifies with three cards above an eight on the board
+	cards := []int{
+		1, 14, 13, 26,
+		2, 3, 4, 5,
+		9, 10, 24, 12, 38,
+	}
+	g := models.NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.Variant = models.VariantOmahaHiLo
+	g.Shuffler = stackHoles(cards, 2, 4)
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, p := range players {
+		if err := g.BuyIn(p, 500); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	act(t, g, players[0], models.ActionAllIn, 500)
+	act(t, g, players[1], models.ActionAllIn, 500)
+
+	results, err := NewGameResolver(g).Resolve()
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 1 || len(results[0].LowWinners) != 0 || results[0].Shares[players[0]] != 1000 {
+		t.Fatalf("expected the high hand to scoop got %+v", results)
+	}
+}
@@ -0,0 +1,86 @@
+// Package rake computes the chips the house takes from a hand, so the game
+// engine in pkg/models and anyone checking a settled game use the same
+// rules.
+package rake
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	// ErrBadPercent is returned for a rake percentage outside 0 to 100%.
+	ErrBadPercent = errors.New("rake percentage must be between 0 and 10000 basis points")
+	// ErrNegative is returned for a negative cap or fee.
+	ErrNegative = errors.New("rake caps and fees must not be negative")
+)
+
+// Rules configures the rake. The zero value takes no rake.
+type Rules struct {
+	Percent      int64       `json:"percent"`         // share of each pot in basis points, 500 is 5%
+	Cap          int64       `json:"cap"`             // most rake per hand, 0 for no cap
+	PlayerCaps   []PlayerCap `json:"player_caps"`     // caps depending on the players dealt in
+	NoFlopNoDrop bool        `json:"no_flop_no_drop"` // no rake on hands that end before the flop
+	Fee          int64       `json:"fee"`             // fee paid to the house on every buy-in
+}
+
+// PlayerCap caps the rake of hands dealt to at least Players players.
+type PlayerCap struct {
+	Players int   `json:"players"`
+	Cap     int64 `json:"cap"`
+}
+
+// Validate checks that the rules are usable.
+func (r Rules) Validate() error {
+	if r.Percent < 0 || r.Percent > 10000 {
+		return ErrBadPercent
+	}
+	if r.Cap < 0 || r.Fee < 0 {
+		return ErrNegative
+	}
+	for _, c := range r.PlayerCaps {
+		if c.Cap < 0 || c.Players < 0 {
+			return ErrNegative
+		}
+	}
+	return nil
+}
+
+// HandCap returns the most rake taken from a hand dealt to players players,
+// the lower of Cap and the player cap with the most players not above
+// players. It returns 0 when no cap applies.
+func (r Rules) HandCap(players int) int64 {
+	limit := r.Cap
+	caps := append([]PlayerCap{}, r.PlayerCaps...)
+	sort.Slice(caps, func(i, j int) bool { return caps[i].Players < caps[j].Players })
+	for i := len(caps) - 1; i >= 0; i-- {
+		if caps[i].Players <= players {
+			if limit == 0 || caps[i].Cap < limit {
+				limit = caps[i].Cap
+			}
+			break
+		}
+	}
+	return limit
+}
+
+// Take returns the rake of each pot of a hand dealt to players players.
+// Percent of every pot is taken, rounded down, starting with the main pot
+// until the hand cap is reached. flop reports whether a flop was dealt.
+func (r Rules) Take(pots []int64, players int, flop bool) []int64 {
+	raked := make([]int64, len(pots))
+	if r.Percent == 0 || (r.NoFlopNoDrop && !flop) {
+		return raked
+	}
+	limit := r.HandCap(players)
+	var total int64
+	for i, amount := range pots {
+		take := amount * r.Percent / 10000
+		if limit > 0 && total+take > limit {
+			take = limit - total
+		}
+		raked[i] = take
+		total += take
+	}
+	return raked
+}
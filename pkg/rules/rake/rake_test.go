@@ -0,0 +1,60 @@
+package rake
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTake(t *testing.T) {
+	cases := []struct {
+		name    string
+		rules   Rules
+		pots    []int64
+		players int
+		flop    bool
+		want    []int64
+	}{
+		{"no rake", Rules{}, []int64{1000}, 2, true, []int64{0}},
+		{"percent rounds down", Rules{Percent: 500}, []int64{1010, 333}, 3, true, []int64{50, 16}},
+		{"hand cap", Rules{Percent: 500, Cap: 60}, []int64{1000, 400}, 3, true, []int64{50, 10}},
+		{"player cap", Rules{Percent: 500, Cap: 60, PlayerCaps: []PlayerCap{{Players: 2, Cap: 20}, {Players: 4, Cap: 40}}}, []int64{1000}, 3, true, []int64{20}},
+		{"player cap above hand cap", Rules{Percent: 500, Cap: 30, PlayerCaps: []PlayerCap{{Players: 2, Cap: 40}}}, []int64{1000}, 6, true, []int64{30}},
+		{"no flop no drop", Rules{Percent: 500, NoFlopNoDrop: true}, []int64{150}, 2, false, []int64{0}},
+		{"preflop without the rule", Rules{Percent: 500}, []int64{150}, 2, false, []int64{7}},
+	}
+	for _, c := range cases {
+		if got := c.rules.Take(c.pots, c.players, c.flop); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: expected %v got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Rules{Percent: 10001}).Validate(); !errors.Is(err, ErrBadPercent) {
+		t.Fatalf("expected ErrBadPercent got %v", err)
+	}
+	if err := (Rules{Fee: -1}).Validate(); !errors.Is(err, ErrNegative) {
+		t.Fatalf("expected ErrNegative got %v", err)
+	}
+	if err := (Rules{Percent: 500, Cap: 300, Fee: 10}).Validate(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestRulesJSON(t *testing.T) {
+	r := Rules{Percent: 500, Cap: 30, PlayerCaps: []PlayerCap{{Players: 2, Cap: 10}}, NoFlopNoDrop: true, Fee: 5}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"percent":500,"cap":30,"player_caps":[{"players":2,"cap":10}],"no_flop_no_drop":true,"fee":5}`
+	if string(data) != want {
+		t.Fatalf("expected %s got %s", want, data)
+	}
+	var back Rules
+	if err := json.Unmarshal(data, &back); err != nil || !reflect.DeepEqual(back, r) {
+		t.Fatalf("expected %+v back got %+v: %v", r, back, err)
+	}
+}
@@ -2,11 +2,9 @@ package validate
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 
-	"github.com/google/uuid"
 	"pokerDB/pkg/models"
+	"pokerDB/pkg/rules/betting"
 )
 
 // ValidationError describes a problem found while checking a game's action log.
@@ -20,26 +18,46 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("entry %d %q: %v", e.Index, e.Entry, e.Err)
 }
 
-func shortID(id uuid.UUID) string {
-	s := id.String()
-	if len(s) > 8 {
-		return s[:8]
+// Unwrap returns the underlying error so callers can match it with errors.Is.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// stackKey returns the key of stacks holding the player's chips: the ID as
+// logged or, for a full ID, its truncated form.
+func stackKey(stacks map[string]int64, id string) string {
+	if _, ok := stacks[id]; !ok && len(id) > 8 {
+		if _, ok := stacks[id[:8]]; ok {
+			return id[:8]
+		}
 	}
-	return s
+	return id
 }
 
 // Validate checks the recorded actions of a game. The optional stacks map
-// contains the starting chip count for each player, keyed by the truncated
-// player ID used in the action log. When provided, chip amounts are verified
-// against raises and calls.
+// contains the starting chip count for each player, keyed by the player ID
+// used in the action log. Version 2 logs name players in full, but stacks
+// keyed by the truncated ID of version 1 logs are matched as well. Every
+// version 2 entry must carry its position in the log as sequence number, so
+// reordered or removed entries fail with models.ErrBadSequence. When
+// provided, chip amounts are verified against raises and calls. Raises are
+// checked against the game's betting structure, so pot-limit raises may not
+// exceed the pot and fixed-limit raises must be exactly one bet.
 func Validate(g *models.Game, stacks map[string]int64) error {
 	if len(g.ActionLog) < 2 {
 		return fmt.Errorf("action log too short")
 	}
 
+	for i, entry := range g.ActionLog {
+		a, err := models.ParseAction(entry)
+		if err == nil && a.Version == models.LogV2 && a.Kind != models.EntryVersion && a.Seq != int64(i) {
+			return &ValidationError{Index: i, Entry: entry, Err: fmt.Errorf("%w: %d", models.ErrBadSequence, a.Seq)}
+		}
+	}
+
 	startIdx := -1
 	for i, entry := range g.ActionLog {
-		if strings.HasPrefix(entry, "G:") {
+		if a, err := models.ParseAction(entry); err == nil && a.Kind == models.EntryStart {
 			startIdx = i
 			break
 		}
@@ -47,13 +65,18 @@ func Validate(g *models.Game, stacks map[string]int64) error {
 	if startIdx == -1 {
 		return fmt.Errorf("missing start entry")
 	}
-	if !strings.HasPrefix(g.ActionLog[len(g.ActionLog)-1], "E:") {
+	if a, err := models.ParseAction(g.ActionLog[len(g.ActionLog)-1]); err != nil || a.Kind != models.EntryEnd {
 		return fmt.Errorf("missing end entry")
 	}
 
-	// track current highest bet and minimum raise size
-	currentBet := g.BigBlind
-	lastRaiseDelta := g.BigBlind
+	// track the betting round with the rules shared with the engine; logs
+	// written before blinds were posted explicitly start with an implied
+	// big blind
+	round := g.Betting.NewRound(g.BigBlind, false)
+	round.Post(g.BigBlind)
+	acted := make(map[string]bool)
+	// chips put into the hand, which bound pot-limit raises
+	var pot int64
 
 	playerBets := make(map[string]int64)
 	if stacks == nil {
@@ -62,30 +85,39 @@ func Validate(g *models.Game, stacks map[string]int64) error {
 
 	for i, entry := range g.ActionLog[startIdx+1 : len(g.ActionLog)-1] {
 		idx := i + startIdx + 1
-		parts := strings.SplitN(entry, ",", 2)
-		if len(parts) != 2 {
-			return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("malformed entry")}
-		}
-		body := parts[0]
-		if len(body) < 9 {
-			return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("invalid body")}
-		}
-		pid := body[:8]
-		code := string(body[8])
-		amtStr := body[9:]
-		amount, err := strconv.ParseInt(amtStr, 10, 64)
+		a, err := models.ParseAction(entry)
 		if err != nil {
-			return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("bad amount")}
+			return &ValidationError{Index: idx, Entry: entry, Err: err}
+		}
+		switch a.Kind {
+		case models.EntryStreet:
+			// a new street starts with fresh bets; preflop blinds are
+			// posted explicitly after the street marker
+			playerBets = make(map[string]int64)
+			round = g.Betting.NewRound(g.BigBlind, a.Street.Name == "turn" || a.Street.Name == "river")
+			acted = make(map[string]bool)
+			if a.Street.Name == "preflop" {
+				pot = 0
+			}
+			continue
+		case models.EntryRake:
+			// rake comes out of the pot before the wins are paid
+			continue
+		case models.EntryStart, models.EntryEnd, models.EntryVersion:
+			return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("unexpected start or end entry")}
 		}
+		pid, code, amount := stackKey(stacks, a.Player), a.Code, a.Amount
 
 		switch code {
 		case models.ActionRaise:
-			if amount <= currentBet {
-				return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("raise below current bet")}
+			if err := round.CheckRaise(amount); err != nil {
+				return &ValidationError{Index: idx, Entry: entry, Err: err}
 			}
-			delta := amount - currentBet
-			if delta < lastRaiseDelta {
-				return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("raise too small")}
+			if acted[pid] {
+				return &ValidationError{Index: idx, Entry: entry, Err: betting.ErrNotReopened}
+			}
+			if err := round.CheckLimit(amount, pot, playerBets[pid]); err != nil {
+				return &ValidationError{Index: idx, Entry: entry, Err: err}
 			}
 			need := amount - playerBets[pid]
 			if s, ok := stacks[pid]; ok && need > s {
@@ -95,28 +127,31 @@ func Validate(g *models.Game, stacks map[string]int64) error {
 				stacks[pid] = s - need
 			}
 			playerBets[pid] = amount
-			currentBet = amount
-			lastRaiseDelta = delta
+			pot += need
+			round.Apply(amount)
+			acted = map[string]bool{pid: true}
 
 		case models.ActionCheck:
-			if currentBet > playerBets[pid] {
+			if round.CurrentBet > playerBets[pid] {
 				// call
-				if amount != currentBet {
+				if amount != round.CurrentBet {
 					return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("call amount mismatch")}
 				}
-				need := currentBet - playerBets[pid]
+				need := round.CurrentBet - playerBets[pid]
 				if s, ok := stacks[pid]; ok && need > s {
 					return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("insufficient chips to call; must all-in")}
 				}
 				if s, ok := stacks[pid]; ok {
 					stacks[pid] = s - need
 				}
-				playerBets[pid] = currentBet
+				playerBets[pid] = round.CurrentBet
+				pot += need
 			} else {
 				if amount != 0 {
 					return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("check amount must be 0")}
 				}
 			}
+			acted[pid] = true
 
 		case models.ActionAllIn:
 			need := amount - playerBets[pid]
@@ -129,15 +164,24 @@ func Validate(g *models.Game, stacks map[string]int64) error {
 				}
 				stacks[pid] = 0
 			}
+			if amount > round.CurrentBet && acted[pid] {
+				return &ValidationError{Index: idx, Entry: entry, Err: betting.ErrNotReopened}
+			}
+			if err := round.CheckLimit(amount, pot, playerBets[pid]); err != nil {
+				return &ValidationError{Index: idx, Entry: entry, Err: err}
+			}
 			playerBets[pid] += need
-			if amount > currentBet {
-				delta := amount - currentBet
-				if delta < lastRaiseDelta {
-					return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("all-in raise too small")}
+			pot += need
+			// an all-in short of a full raise moves the bet without
+			// reopening the action
+			if round.Apply(amount) {
+				for p := range acted {
+					if round.Reopened(playerBets[p]) {
+						delete(acted, p)
+					}
 				}
-				currentBet = amount
-				lastRaiseDelta = delta
 			}
+			acted[pid] = true
 
 		case models.ActionFold:
 			if amount != 0 {
@@ -145,11 +189,55 @@ func Validate(g *models.Game, stacks map[string]int64) error {
 			}
 			playerBets[pid] = 0
 
-		case models.ActionJoin, models.ActionQuit, models.ActionSeat:
-			// joining, quitting and seat selections do not impact validation
+		case models.ActionAnte, models.ActionSmallBlind, models.ActionBigBlind, models.ActionStraddle, models.ActionDeadBlind:
+			if s, ok := stacks[pid]; ok {
+				if amount > s {
+					return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("insufficient chips to post")}
+				}
+				stacks[pid] = s - amount
+			}
+			pot += amount
+			// antes and missed small blinds are dead
+			if code != models.ActionAnte && code != models.ActionDeadBlind {
+				playerBets[pid] += amount
+				// a big blind or straddle posted short by an all-in player
+				// still sets the bet to call at its full size
+				bet, full := playerBets[pid], amount
+				switch code {
+				case models.ActionBigBlind:
+					full = g.BigBlind
+				case models.ActionStraddle:
+					full = 2 * g.BigBlind
+				}
+				if amount < full {
+					bet += full - amount
+				}
+				round.Post(bet)
+			}
+
+		case models.ActionUncalled:
+			if amount > playerBets[pid] {
+				return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("returned more than bet")}
+			}
+			if s, ok := stacks[pid]; ok {
+				stacks[pid] = s + amount
+			}
+			playerBets[pid] -= amount
+			pot -= amount
+			round.CurrentBet = playerBets[pid]
+
+		case models.ActionWin, models.ActionBuyIn, models.ActionRebuy, models.ActionTopUp:
+			if s, ok := stacks[pid]; ok {
+				stacks[pid] = s + amount
+			}
+
+		case models.ActionJoin, models.ActionQuit, models.ActionSeat, models.ActionRunTwice, models.ActionFee, models.ActionTimeBank,
+			models.ActionSitOut, models.ActionSitIn:
+			// joining, quitting, seat selections, run choices, fees, time
+			// bank extensions and sitting out do not impact validation
 
 		default:
-			return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("unknown action %s", code)}
+			return &ValidationError{Index: idx, Entry: entry, Err: fmt.Errorf("unexpected action %s", code)}
 		}
 	}
 	return nil
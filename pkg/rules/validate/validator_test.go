@@ -1,10 +1,13 @@
 package validate
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
 	"pokerDB/pkg/models"
+	"pokerDB/pkg/rules/betting"
 )
 
 func TestValidateGameValid(t *testing.T) {
@@ -24,10 +27,11 @@ func TestValidateGameValid(t *testing.T) {
 	if err := g.Start(); err != nil {
 		t.Fatalf("start: %v", err)
 	}
-	if err := g.AddAction(p1, models.ActionCheck, 100); err != nil {
+	g.DealHands()
+	if err := g.AddAction(p1, models.ActionRaise, 200); err != nil {
 		t.Fatalf("action1: %v", err)
 	}
-	if err := g.AddAction(p2, models.ActionRaise, 300); err != nil {
+	if err := g.AddAction(p2, models.ActionRaise, 400); err != nil {
 		t.Fatalf("action2: %v", err)
 	}
 	if err := g.AddAction(p1, models.ActionFold, 0); err != nil {
@@ -63,19 +67,137 @@ func TestValidateGameInvalidRaise(t *testing.T) {
 	if err := g.Start(); err != nil {
 		t.Fatalf("start: %v", err)
 	}
+	g.DealHands()
 	if err := g.AddAction(p1, models.ActionRaise, 200); err != nil {
 		t.Fatalf("action1: %v", err)
 	}
-	if err := g.AddAction(p2, models.ActionRaise, 250); err != nil {
+	if err := g.AddAction(p2, models.ActionRaise, 300); err != nil {
 		t.Fatalf("action2: %v", err)
 	}
+	if err := g.AddAction(p2, models.ActionRaise, 250); err == nil {
+		t.Fatalf("expected the engine to reject a short raise")
+	}
 	if err := g.End(); err != nil {
 		t.Fatalf("end: %v", err)
 	}
+	// the engine refuses short raises, so tamper with the log instead
+	tamper(t, g, p2.String()+models.ActionRaise+"300", p2.String()+models.ActionRaise+"250")
 
-	if err := Validate(g, nil); err == nil {
+	err := Validate(g, nil)
+	if err == nil {
 		t.Fatalf("expected error")
 	}
+	if !errors.Is(err, betting.ErrRaiseTooSmall) {
+		t.Fatalf("expected raise too small got %v", err)
+	}
+}
+
+func TestValidateIncompleteAllIn(t *testing.T) {
+	g := models.NewGame(uuid.New(), 3)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, amount := range []int64{1000, 1000, 250} {
+		if err := g.BuyIn(players[i], amount); err != nil {
+			t.Fatalf("buyin %d: %v", i, err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	// the button opens, the small blind calls and the big blind's all-in
+	// raise of 50 is short of a full raise
+	steps := []struct {
+		player int
+		code   string
+		amount int64
+	}{
+		{0, models.ActionRaise, 200},
+		{1, models.ActionCheck, 200},
+		{2, models.ActionAllIn, 250},
+		{0, models.ActionCheck, 250},
+		{1, models.ActionCheck, 250},
+	}
+	for i, s := range steps {
+		if err := g.AddAction(players[s.player], s.code, s.amount); err != nil {
+			t.Fatalf("action %d: %v", i, err)
+		}
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if err := Validate(g, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// re-raising after the short all-in is not allowed
+	tamper(t, g, players[0].String()+models.ActionCheck+"250", players[0].String()+models.ActionRaise+"500")
+	if err := Validate(g, nil); !errors.Is(err, betting.ErrNotReopened) {
+		t.Fatalf("expected betting not reopened got %v", err)
+	}
+}
+
+func TestValidateShortAllInsReopen(t *testing.T) {
+	g := models.NewGame(uuid.New(), 4)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	for i, amount := range []int64{250, 1000, 300, 1000} {
+		if err := g.BuyIn(players[i], amount); err != nil {
+			t.Fatalf("buyin %d: %v", i, err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	// two all-ins of 50 more make a full raise over the raise to 200,
+	// which reopens the raiser but not the small blind who called in
+	// between
+	steps := []struct {
+		player int
+		code   string
+		amount int64
+	}{
+		{3, models.ActionRaise, 200},
+		{0, models.ActionAllIn, 250},
+		{1, models.ActionCheck, 250},
+		{2, models.ActionAllIn, 300},
+		{3, models.ActionCheck, 300},
+		{1, models.ActionCheck, 300},
+	}
+	for i, s := range steps {
+		if err := g.AddAction(players[s.player], s.code, s.amount); err != nil {
+			t.Fatalf("action %d: %v", i, err)
+		}
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if err := Validate(g, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the small blind called the first all-in and may not raise again
+	tamper(t, g, players[1].String()+models.ActionCheck+"300", players[1].String()+models.ActionRaise+"400")
+	if err := Validate(g, nil); !errors.Is(err, betting.ErrNotReopened) {
+		t.Fatalf("expected betting not reopened got %v", err)
+	}
+}
+
+// tamper replaces the body of the first log entry that reads from, keeping
+// its sequence number and timestamp.
+func tamper(t *testing.T, g *models.Game, from, to string) {
+	t.Helper()
+	for i, entry := range g.ActionLog {
+		seq := entry[:strings.Index(entry, ";")+1]
+		if strings.HasPrefix(entry, seq+from+",") {
+			g.ActionLog[i] = seq + to + strings.TrimPrefix(entry, seq+from)
+			return
+		}
+	}
+	t.Fatalf("no entry %s in %v", from, g.ActionLog)
 }
 
 func TestValidateGameInsufficientCall(t *testing.T) {
@@ -95,13 +217,14 @@ func TestValidateGameInsufficientCall(t *testing.T) {
 	if err := g.Start(); err != nil {
 		t.Fatalf("start: %v", err)
 	}
-	if err := g.AddAction(p1, models.ActionCheck, 100); err != nil {
+	g.DealHands()
+	if err := g.AddAction(p1, models.ActionRaise, 200); err != nil {
 		t.Fatalf("action1: %v", err)
 	}
-	if err := g.AddAction(p2, models.ActionRaise, 300); err != nil {
+	if err := g.AddAction(p2, models.ActionRaise, 400); err != nil {
 		t.Fatalf("action2: %v", err)
 	}
-	if err := g.AddAction(p1, models.ActionCheck, 300); err != nil {
+	if err := g.AddAction(p1, models.ActionCheck, 400); err != nil {
 		t.Fatalf("action3: %v", err)
 	}
 	if err := g.End(); err != nil {
@@ -109,10 +232,144 @@ func TestValidateGameInsufficientCall(t *testing.T) {
 	}
 
 	stacks := map[string]int64{
-		p1.String()[:8]: 200,
+		p1.String()[:8]: 300,
 		p2.String()[:8]: 1000,
 	}
 	if err := Validate(g, stacks); err == nil {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestValidateShortBigBlind(t *testing.T) {
+	g := models.NewGame(uuid.New(), 3)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, amount := range []int64{1000, 1000, 30} {
+		if err := g.BuyIn(players[i], amount); err != nil {
+			t.Fatalf("buyin %d: %v", i, err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	if err := g.AddAction(players[0], models.ActionRaise, 200); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if err := g.AddAction(players[1], models.ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if err := Validate(g, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the big blind of 30 is all-in but the minimum raise is still to 200
+	tamper(t, g, players[0].String()+models.ActionRaise+"200", players[0].String()+models.ActionRaise+"150")
+	if err := Validate(g, nil); !errors.Is(err, betting.ErrRaiseTooSmall) {
+		t.Fatalf("expected raise too small got %v", err)
+	}
+}
+
+func TestValidateV1Log(t *testing.T) {
+	g := models.NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.ActionLog = models.ActionLog{
+		"c0ffee00B500,1692300000",
+		"c0ffee01B500,1692300000",
+		"G:50:100:0:0:0,1692300000",
+		"D:preflop,1692300000",
+		"c0ffee00L50,1692300000",
+		"c0ffee01G100,1692300000",
+		"c0ffee00R300,1692300001",
+		"c0ffee01C300,1692300002",
+		"E:c0ffee00=0:c0ffee01=0,1692300003",
+	}
+	stacks := map[string]int64{"c0ffee00": 500, "c0ffee01": 500}
+	if err := Validate(g, stacks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.ActionLog[6] = "c0ffee00R150,1692300001"
+	if err := Validate(g, nil); !errors.Is(err, betting.ErrRaiseTooSmall) {
+		t.Fatalf("expected a short raise error got %v", err)
+	}
+}
+
+func TestValidateSequence(t *testing.T) {
+	g := models.NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	p1 := uuid.New()
+	p2 := uuid.New()
+	if err := g.BuyIn(p1, 500); err != nil {
+		t.Fatalf("buyin p1: %v", err)
+	}
+	if err := g.BuyIn(p2, 500); err != nil {
+		t.Fatalf("buyin p2: %v", err)
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	if err := g.AddAction(p1, models.ActionFold, 0); err != nil {
+		t.Fatalf("fold: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if err := Validate(g, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the buy-ins read the same in either order, but not their sequence
+	g.ActionLog[1], g.ActionLog[2] = g.ActionLog[2], g.ActionLog[1]
+	err := Validate(g, nil)
+	var validationErr *ValidationError
+	if !errors.Is(err, models.ErrBadSequence) || !errors.As(err, &validationErr) || validationErr.Index != 1 {
+		t.Fatalf("expected a bad sequence at entry 1 got %v", err)
+	}
+}
+
+func TestValidatePotLimit(t *testing.T) {
+	g := models.NewGame(uuid.New(), 2)
+	g.SmallBlind = 50
+	g.BigBlind = 100
+	g.Betting = betting.Limits{Structure: betting.PotLimit}
+	p1 := uuid.New()
+	p2 := uuid.New()
+	for _, p := range []uuid.UUID{p1, p2} {
+		if err := g.BuyIn(p, 1000); err != nil {
+			t.Fatalf("buyin: %v", err)
+		}
+	}
+	if err := g.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	g.DealHands()
+	// the button raises the pot to 300, the big blind re-raises the pot to
+	// 300 + 400 + 200
+	if err := g.AddAction(p1, models.ActionRaise, 300); err != nil {
+		t.Fatalf("action1: %v", err)
+	}
+	if err := g.AddAction(p2, models.ActionRaise, 900); err != nil {
+		t.Fatalf("action2: %v", err)
+	}
+	if err := g.AddAction(p1, models.ActionFold, 0); err != nil {
+		t.Fatalf("action3: %v", err)
+	}
+	if err := g.End(); err != nil {
+		t.Fatalf("end: %v", err)
+	}
+	if err := Validate(g, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tamper(t, g, p2.String()+models.ActionRaise+"900", p2.String()+models.ActionRaise+"950")
+	if err := Validate(g, nil); !errors.Is(err, betting.ErrRaiseTooLarge) {
+		t.Fatalf("expected raise too large got %v", err)
+	}
+}
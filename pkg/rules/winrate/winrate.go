@@ -1,21 +1,14 @@
 package winrate
 
 import (
+	"pokerDB/pkg/constants"
 	"pokerDB/pkg/rules/evaluation"
 )
 
 // cardToEval converts a card represented as 1-52 (suit first) into the card
 // representation used by the evaluation package which is ordered by rank.
 func cardToEval(c int) evaluation.Card {
-	r := uint8((c - 1) % 13) // our numbering uses A=0
-	var rank uint8
-	if r == 0 {
-		rank = 12
-	} else {
-		rank = r - 1
-	}
-	suit := uint8((c - 1) / 13)
-	return evaluation.NewCardFromId((rank << 2) | suit)
+	return evaluation.NewCardFromInt(c)
 }
 
 // Calculate takes player hands and community cards (board) and returns the
@@ -23,6 +16,39 @@ func cardToEval(c int) evaluation.Card {
 // Each hand should contain exactly two cards represented as integers 1-52.
 // The board may contain 0 to 5 cards, also encoded as 1-52.
 func Calculate(hands [][]int, board []int) []float64 {
+	return calculate(hands, board, constants.CardSequence, func(hole, board []evaluation.Card) evaluation.Rank {
+		return evaluation.EvaluateCards(append(hole, board...)...)
+	})
+}
+
+// CalculateOmaha works like Calculate for Omaha hands of four to six cards,
+// which make their best hand from exactly two hole cards and three board
+// cards.
+func CalculateOmaha(hands [][]int, board []int) []float64 {
+	return calculate(hands, board, constants.CardSequence, evaluation.EvaluateOmaha)
+}
+
+// CalculateShortDeck works like Calculate for short-deck hold'em, dealing
+// the board from the 36 card deck and ranking hands by rules.
+func CalculateShortDeck(hands [][]int, board []int, rules evaluation.ShortDeck) []float64 {
+	return calculate(hands, board, constants.ShortDeckSequence, func(hole, board []evaluation.Card) evaluation.Rank {
+		return rules.EvaluateCards(append(hole, board...)...)
+	})
+}
+
+// calculate enumerates every board completing the known cards from deck and
+// ranks the hands on each with rank.
+func calculate(hands [][]int, board []int, deck []int, rank func(hole, board []evaluation.Card) evaluation.Rank) []float64 {
+	// hole cards leave room for the board so Calculate ranks them without
+	// allocating
+	holes := make([][]evaluation.Card, len(hands))
+	for i, h := range hands {
+		holes[i] = make([]evaluation.Card, len(h), len(h)+5)
+		for k, c := range h {
+			holes[i][k] = cardToEval(c)
+		}
+	}
+	cards := make([]evaluation.Card, 5)
 	// prepare deck of remaining cards
 	used := make(map[int]bool)
 	for _, h := range hands {
@@ -33,10 +59,10 @@ func Calculate(hands [][]int, board []int) []float64 {
 	for _, c := range board {
 		used[c] = true
 	}
-	remaining := make([]int, 0, 52-len(used))
-	for i := 1; i <= 52; i++ {
-		if !used[i] {
-			remaining = append(remaining, i)
+	remaining := make([]int, 0, len(deck))
+	for _, c := range deck {
+		if !used[c] {
+			remaining = append(remaining, c)
 		}
 	}
 
@@ -49,21 +75,18 @@ func Calculate(hands [][]int, board []int) []float64 {
 	choose = func(start int, picked []int) {
 		if len(picked) == need {
 			fullBoard := append(board[:], picked...)
+			for k, bc := range fullBoard {
+				cards[k] = cardToEval(bc)
+			}
 			// evaluate hands
-			bestVal := uint16(65535)
+			var best evaluation.Rank
 			winners := []int{}
-			for i, h := range hands {
-				all := make([]evaluation.Card, 0, 7)
-				all = append(all, cardToEval(h[0]), cardToEval(h[1]))
-				for _, bc := range fullBoard {
-					all = append(all, cardToEval(bc))
-				}
-				r := evaluation.EvaluateCards(all...)
-				v := r.GetValue()
-				if v < bestVal {
-					bestVal = v
+			for i, h := range holes {
+				r := rank(h, cards[:len(fullBoard)])
+				if len(winners) == 0 || r.Compare(best) < 0 {
+					best = r
 					winners = []int{i}
-				} else if v == bestVal {
+				} else if r.Compare(best) == 0 {
 					winners = append(winners, i)
 				}
 			}
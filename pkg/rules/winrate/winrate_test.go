@@ -3,6 +3,8 @@ package winrate
 import (
 	"math"
 	"testing"
+
+	"pokerDB/pkg/rules/evaluation"
 )
 
 func nearlyEqual(a, b, eps float64) bool {
@@ -44,3 +46,29 @@ func TestFlopAAvsKK(t *testing.T) {
 		t.Errorf("unexpected win rates: %v", rates)
 	}
 }
+
+func TestOmahaRiverFlushNeedsTwoSuitedCards(t *testing.T) {
+	// the board holds four cards of the first suit; only the second hand
+	// holds two of them
+	hands := [][]int{{1, 16, 29, 43}, {3, 4, 37, 50}}
+	board := []int{2, 5, 8, 9, 23}
+	rates := CalculateOmaha(hands, board)
+	if len(rates) != 2 || rates[0] != 0 || rates[1] != 1 {
+		t.Errorf("unexpected win rates: %v", rates)
+	}
+}
+
+func TestShortDeckFlushBeatsFullHouse(t *testing.T) {
+	// on the turn the first hand holds aces full of nines and the second
+	// four cards of the first suit
+	hands := [][]int{{14, 27}, {11, 46}}
+	board := []int{1, 9, 22, 6}
+	if std := Calculate(hands, board); std[1] != 0 {
+		t.Errorf("a flush never beats a full house with a full deck: %v", std)
+	}
+	// five of the 28 unseen short-deck cards complete the flush
+	short := CalculateShortDeck(hands, board, evaluation.ShortDeck{})
+	if !nearlyEqual(short[1], 5.0/28, 1e-9) {
+		t.Errorf("unexpected short-deck win rates: %v", short)
+	}
+}